@@ -11,6 +11,8 @@ import (
 type Node interface {
 	TokenLiteral() string
 	String() string
+	Pos() token.Position //节点第一个字符的位置
+	End() token.Position //节点最后一个字符之后的位置
 }
 
 // 语句,不会产生值，例如 int a = 10; return 0;
@@ -37,10 +39,12 @@ func (p *Program) TokenLiteral() string {
 	}
 }
 
+// 解构时Name为nil，Pattern为 *ArrayPattern 或 *HashPattern
 type LetStatement struct {
-	Token token.Token
-	Name  *Identifier
-	Value Expression
+	Token   token.Token
+	Name    *Identifier
+	Pattern Expression
+	Value   Expression
 }
 type ReturnStatement struct {
 	Token       token.Token
@@ -58,6 +62,10 @@ type IntegerLiteral struct {
 	Token token.Token
 	Value int64
 }
+type FloatLiteral struct {
+	Token token.Token
+	Value float64
+}
 type PrefixExpression struct {
 	Token    token.Token
 	Operator string
@@ -73,9 +81,13 @@ type Boolean struct {
 	Token token.Token
 	Value bool
 }
+type NullLiteral struct {
+	Token token.Token
+}
 type BlockStatement struct {
 	Token      token.Token
 	Statements []Statement
+	RBrace     token.Token
 }
 type IfExpression struct {
 	Token       token.Token
@@ -83,33 +95,88 @@ type IfExpression struct {
 	Consequence *BlockStatement
 	Alternative *BlockStatement
 }
+
+// Defaults与Parameters一一对应，没有默认值的参数对应nil；Rest为 ...rest 参数，没有时为nil
 type FunctionLiteral struct {
 	Token      token.Token
 	Parameters []*Identifier
+	Defaults   []Expression
+	Rest       *Identifier
 	Body       *BlockStatement
 }
+
+/*
+Keywords为按参数名传入的参数 f(1, name: value) ，在源码中总是位于Arguments之后
+Optional为true时是可选调用 f?.() ，f为null时不调用，下标、切片和字段的Optional同理
+*/
 type CallExpression struct {
 	Token     token.Token
 	Function  Expression
 	Arguments []Expression
+	Keywords  []*KeywordArgument
+	Optional  bool
+	RParen    token.Token
+}
+
+// 关键字参数 name: value
+type KeywordArgument struct {
+	Name  *Identifier
+	Value Expression
 }
 type StringLiteral struct {
 	Token token.Token
 	Value string
 }
+
+// 带插值的字符串，Parts中字符串片段为*StringLiteral，其余为插值表达式
+type InterpolatedString struct {
+	Token token.Token
+	Parts []Expression
+}
 type ArrayLiteral struct {
 	Token    token.Token
 	Elements []Expression
+	RBracket token.Token
 }
 type IndexExpression struct {
-	Token token.Token
-	Left  Expression
-	Index Expression
+	Token    token.Token
+	Left     Expression
+	Index    Expression
+	Optional bool //a?[k]
+	RBracket token.Token
+}
+
+// 切片 a[start:stop:step]，省略的部分为nil
+type SliceExpression struct {
+	Token    token.Token
+	Left     Expression
+	Start    Expression
+	Stop     Expression
+	Step     Expression
+	Optional bool //a?[start:stop]
+	RBracket token.Token
 }
 
+// obj.name ，用于访问哈希中的字段和调用方法
+type PropertyExpression struct {
+	Token    token.Token
+	Object   Expression
+	Property *Identifier
+	Optional bool //obj?.name
+}
+
+// Order按源码顺序记录Pairs中的key和 ...spread ，求值时按此顺序进行，后出现的key覆盖先出现的
 type HashLiteral struct {
+	Token  token.Token
+	Pairs  map[Expression]Expression
+	Order  []Expression
+	RBrace token.Token
+}
+
+// ...value ，在调用参数、数组和哈希表中展开value
+type SpreadExpression struct {
 	Token token.Token
-	Pairs map[Expression]Expression
+	Value Expression
 }
 
 func (hl *HashLiteral) expressionNode()      {}
@@ -118,8 +185,12 @@ func (hl *HashLiteral) String() string {
 	var out bytes.Buffer
 	pairs := []string{}
 
-	for key, value := range hl.Pairs {
-		pairs = append(pairs, key.String()+":"+value.String())
+	for _, key := range hl.Order {
+		if spread, ok := key.(*SpreadExpression); ok {
+			pairs = append(pairs, spread.String())
+			continue
+		}
+		pairs = append(pairs, key.String()+":"+hl.Pairs[key].String())
 	}
 	out.WriteString("{")
 	out.WriteString(strings.Join(pairs, ","))
@@ -135,12 +206,56 @@ func (ie *IndexExpression) String() string {
 
 	out.WriteString("(")
 	out.WriteString(ie.Left.String())
+	if ie.Optional {
+		out.WriteString("?")
+	}
 	out.WriteString("[")
 	out.WriteString(ie.Index.String())
 	out.WriteString("]")
 	out.WriteString(")")
 	return out.String()
 }
+func (se *SliceExpression) expressionNode()      {}
+func (se *SliceExpression) TokenLiteral() string { return se.Token.Literal }
+func (se *SliceExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(se.Left.String())
+	if se.Optional {
+		out.WriteString("?")
+	}
+	out.WriteString("[")
+	if se.Start != nil {
+		out.WriteString(se.Start.String())
+	}
+	out.WriteString(":")
+	if se.Stop != nil {
+		out.WriteString(se.Stop.String())
+	}
+	if se.Step != nil {
+		out.WriteString(":")
+		out.WriteString(se.Step.String())
+	}
+	out.WriteString("]")
+	out.WriteString(")")
+	return out.String()
+}
+func (pe *PropertyExpression) expressionNode()      {}
+func (pe *PropertyExpression) TokenLiteral() string { return pe.Token.Literal }
+func (pe *PropertyExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(pe.Object.String())
+	if pe.Optional {
+		out.WriteString("?")
+	}
+	out.WriteString(".")
+	out.WriteString(pe.Property.String())
+	out.WriteString(")")
+	return out.String()
+}
 func (al *ArrayLiteral) expressionNode()      {}
 func (al *ArrayLiteral) TokenLiteral() string { return al.Token.Literal }
 func (al *ArrayLiteral) String() string {
@@ -155,11 +270,31 @@ func (al *ArrayLiteral) String() string {
 
 	return out.String()
 }
-func (sl *StringLiteral) expressionNode()       {}
-func (sl *StringLiteral) TokenLiteral() string  { return sl.Token.Literal }
-func (sl *StringLiteral) String() string        { return sl.Token.Literal }
-func (ce *CallExpression) expressionNode()      {}
-func (ce *CallExpression) TokenLiteral() string { return ce.Token.Literal }
+func (sl *StringLiteral) expressionNode()           {}
+func (sl *StringLiteral) TokenLiteral() string      { return sl.Token.Literal }
+func (sl *StringLiteral) String() string            { return sl.Token.Literal }
+func (is *InterpolatedString) expressionNode()      {}
+func (is *InterpolatedString) TokenLiteral() string { return is.Token.Literal }
+func (is *InterpolatedString) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("\"")
+	for _, part := range is.Parts {
+		if sl, ok := part.(*StringLiteral); ok {
+			out.WriteString(sl.Value)
+		} else {
+			out.WriteString("${" + part.String() + "}")
+		}
+	}
+	out.WriteString("\"")
+
+	return out.String()
+}
+func (se *SpreadExpression) expressionNode()      {}
+func (se *SpreadExpression) TokenLiteral() string { return se.Token.Literal }
+func (se *SpreadExpression) String() string       { return "..." + se.Value.String() }
+func (ce *CallExpression) expressionNode()        {}
+func (ce *CallExpression) TokenLiteral() string   { return ce.Token.Literal }
 func (ce *CallExpression) String() string {
 	var out bytes.Buffer
 
@@ -167,7 +302,13 @@ func (ce *CallExpression) String() string {
 	for _, a := range ce.Arguments {
 		args = append(args, a.String())
 	}
+	for _, kw := range ce.Keywords {
+		args = append(args, kw.Name.String()+": "+kw.Value.String())
+	}
 	out.WriteString(ce.Function.String())
+	if ce.Optional {
+		out.WriteString("?.")
+	}
 	out.WriteString("(")
 	out.WriteString(strings.Join(args, ", "))
 	out.WriteString(")")
@@ -186,9 +327,15 @@ func (i *Identifier) String() string                 { return i.Value }
 func (it *IntegerLiteral) expressionNode()           {}
 func (it *IntegerLiteral) TokenLiteral() string      { return it.Token.Literal }
 func (it *IntegerLiteral) String() string            { return it.Token.Literal }
+func (fl *FloatLiteral) expressionNode()             {}
+func (fl *FloatLiteral) TokenLiteral() string        { return fl.Token.Literal }
+func (fl *FloatLiteral) String() string              { return fl.Token.Literal }
 func (bl *Boolean) expressionNode()                  {}
 func (bl *Boolean) TokenLiteral() string             { return bl.Token.Literal }
 func (bl *Boolean) String() string                   { return bl.Token.Literal }
+func (nl *NullLiteral) expressionNode()              {}
+func (nl *NullLiteral) TokenLiteral() string         { return nl.Token.Literal }
+func (nl *NullLiteral) String() string               { return nl.Token.Literal }
 func (ie *IfExpression) expressionNode()             {}
 func (ie *IfExpression) TokenLiteral() string        { return ie.Token.Literal }
 func (ie *IfExpression) String() string {
@@ -251,11 +398,17 @@ func (p *Program) String() string {
 	return out.String()
 }
 
+// const NAME = value 与let共用LetStatement，Token为const
+func (ls *LetStatement) IsConst() bool { return ls.Token.Type == token.CONST }
 func (ls *LetStatement) String() string {
 	var out bytes.Buffer
 
 	out.WriteString(ls.TokenLiteral() + " ")
-	out.WriteString(ls.Name.String() + " ")
+	if ls.Pattern != nil {
+		out.WriteString(ls.Pattern.String() + " ")
+	} else {
+		out.WriteString(ls.Name.String() + " ")
+	}
 	out.WriteString("= ")
 	if ls.Value != nil {
 		out.WriteString(ls.Value.String())
@@ -290,8 +443,15 @@ func (fl *FunctionLiteral) String() string {
 	var out bytes.Buffer
 
 	params := []string{}
-	for _, p := range fl.Parameters {
-		params = append(params, p.String())
+	for i, p := range fl.Parameters {
+		if i < len(fl.Defaults) && fl.Defaults[i] != nil {
+			params = append(params, p.String()+" = "+fl.Defaults[i].String())
+		} else {
+			params = append(params, p.String())
+		}
+	}
+	if fl.Rest != nil {
+		params = append(params, "..."+fl.Rest.String())
 	}
 	out.WriteString(fl.TokenLiteral())
 	out.WriteString("(")
@@ -301,3 +461,185 @@ func (fl *FunctionLiteral) String() string {
 
 	return out.String()
 }
+
+// 以下为各节点的位置信息，End为节点最后一个字符之后的位置
+// 解析出错时子节点可能为nil，此时退回到节点自身的token
+
+func (p *Program) Pos() token.Position {
+	if len(p.Statements) > 0 {
+		return p.Statements[0].Pos()
+	}
+	return token.Position{}
+}
+func (p *Program) End() token.Position {
+	if len(p.Statements) > 0 {
+		return p.Statements[len(p.Statements)-1].End()
+	}
+	return token.Position{}
+}
+func (ls *LetStatement) Pos() token.Position { return ls.Token.Pos }
+func (ls *LetStatement) End() token.Position {
+	if ls.Value != nil {
+		return ls.Value.End()
+	}
+	if ls.Name != nil {
+		return ls.Name.End()
+	}
+	if ls.Pattern != nil {
+		return ls.Pattern.End()
+	}
+	return ls.Token.End
+}
+func (rs *ReturnStatement) Pos() token.Position { return rs.Token.Pos }
+func (rs *ReturnStatement) End() token.Position {
+	if rs.ReturnValue != nil {
+		return rs.ReturnValue.End()
+	}
+	return rs.Token.End
+}
+func (es *ExpressionStatement) Pos() token.Position {
+	if es.Expression != nil {
+		return es.Expression.Pos()
+	}
+	return es.Token.Pos
+}
+func (es *ExpressionStatement) End() token.Position {
+	if es.Expression != nil {
+		return es.Expression.End()
+	}
+	return es.Token.End
+}
+func (i *Identifier) Pos() token.Position        { return i.Token.Pos }
+func (i *Identifier) End() token.Position        { return i.Token.End }
+func (it *IntegerLiteral) Pos() token.Position   { return it.Token.Pos }
+func (it *IntegerLiteral) End() token.Position   { return it.Token.End }
+func (fl *FloatLiteral) Pos() token.Position     { return fl.Token.Pos }
+func (fl *FloatLiteral) End() token.Position     { return fl.Token.End }
+func (bl *Boolean) Pos() token.Position          { return bl.Token.Pos }
+func (bl *Boolean) End() token.Position          { return bl.Token.End }
+func (nl *NullLiteral) Pos() token.Position      { return nl.Token.Pos }
+func (nl *NullLiteral) End() token.Position      { return nl.Token.End }
+func (sl *StringLiteral) Pos() token.Position    { return sl.Token.Pos }
+func (sl *StringLiteral) End() token.Position    { return sl.Token.End }
+func (pe *PrefixExpression) Pos() token.Position { return pe.Token.Pos }
+func (pe *PrefixExpression) End() token.Position {
+	if pe.Right != nil {
+		return pe.Right.End()
+	}
+	return pe.Token.End
+}
+func (ie *InfixExpression) Pos() token.Position {
+	if ie.Left != nil {
+		return ie.Left.Pos()
+	}
+	return ie.Token.Pos
+}
+func (ie *InfixExpression) End() token.Position {
+	if ie.Right != nil {
+		return ie.Right.End()
+	}
+	return ie.Token.End
+}
+func (bs *BlockStatement) Pos() token.Position { return bs.Token.Pos }
+func (bs *BlockStatement) End() token.Position {
+	if bs.RBrace.End.IsValid() {
+		return bs.RBrace.End
+	}
+	if len(bs.Statements) > 0 {
+		return bs.Statements[len(bs.Statements)-1].End()
+	}
+	return bs.Token.End
+}
+func (ie *IfExpression) Pos() token.Position { return ie.Token.Pos }
+func (ie *IfExpression) End() token.Position {
+	if ie.Alternative != nil {
+		return ie.Alternative.End()
+	}
+	if ie.Consequence != nil {
+		return ie.Consequence.End()
+	}
+	return ie.Token.End
+}
+func (fl *FunctionLiteral) Pos() token.Position { return fl.Token.Pos }
+func (fl *FunctionLiteral) End() token.Position {
+	if fl.Body != nil {
+		return fl.Body.End()
+	}
+	return fl.Token.End
+}
+func (se *SpreadExpression) Pos() token.Position { return se.Token.Pos }
+func (se *SpreadExpression) End() token.Position {
+	if se.Value != nil {
+		return se.Value.End()
+	}
+	return se.Token.End
+}
+func (ce *CallExpression) Pos() token.Position {
+	if ce.Function != nil {
+		return ce.Function.Pos()
+	}
+	return ce.Token.Pos
+}
+func (ce *CallExpression) End() token.Position {
+	if ce.RParen.End.IsValid() {
+		return ce.RParen.End
+	}
+	return ce.Token.End
+}
+func (is *InterpolatedString) Pos() token.Position { return is.Token.Pos }
+func (is *InterpolatedString) End() token.Position {
+	if len(is.Parts) > 0 && is.Parts[len(is.Parts)-1] != nil {
+		return is.Parts[len(is.Parts)-1].End()
+	}
+	return is.Token.End
+}
+func (al *ArrayLiteral) Pos() token.Position { return al.Token.Pos }
+func (al *ArrayLiteral) End() token.Position {
+	if al.RBracket.End.IsValid() {
+		return al.RBracket.End
+	}
+	return al.Token.End
+}
+func (ie *IndexExpression) Pos() token.Position {
+	if ie.Left != nil {
+		return ie.Left.Pos()
+	}
+	return ie.Token.Pos
+}
+func (ie *IndexExpression) End() token.Position {
+	if ie.RBracket.End.IsValid() {
+		return ie.RBracket.End
+	}
+	return ie.Token.End
+}
+func (se *SliceExpression) Pos() token.Position {
+	if se.Left != nil {
+		return se.Left.Pos()
+	}
+	return se.Token.Pos
+}
+func (se *SliceExpression) End() token.Position {
+	if se.RBracket.End.IsValid() {
+		return se.RBracket.End
+	}
+	return se.Token.End
+}
+func (pe *PropertyExpression) Pos() token.Position {
+	if pe.Object != nil {
+		return pe.Object.Pos()
+	}
+	return pe.Token.Pos
+}
+func (pe *PropertyExpression) End() token.Position {
+	if pe.Property != nil {
+		return pe.Property.End()
+	}
+	return pe.Token.End
+}
+func (hl *HashLiteral) Pos() token.Position { return hl.Token.Pos }
+func (hl *HashLiteral) End() token.Position {
+	if hl.RBrace.End.IsValid() {
+		return hl.RBrace.End
+	}
+	return hl.Token.End
+}
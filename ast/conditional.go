@@ -0,0 +1,76 @@
+package ast
+
+import (
+	"bytes"
+	"interpreter/token"
+	"strings"
+)
+
+// cond ? consequence : alternative
+type ConditionalExpression struct {
+	Token       token.Token
+	Condition   Expression
+	Consequence Expression
+	Alternative Expression
+}
+
+/*
+连续的大小比较 0 <= x < 10 ，等价于 0 <= x && x < 10 ，但中间的操作数只求值一次
+Operands比Operators多一个，Operators[i]比较的是Operands[i]和Operands[i+1]
+*/
+type ComparisonChain struct {
+	Token     token.Token
+	Operands  []Expression
+	Operators []string
+}
+
+func (ce *ConditionalExpression) expressionNode()      {}
+func (ce *ConditionalExpression) TokenLiteral() string { return ce.Token.Literal }
+func (ce *ConditionalExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(ce.Condition.String())
+	out.WriteString(" ? ")
+	out.WriteString(ce.Consequence.String())
+	out.WriteString(" : ")
+	out.WriteString(ce.Alternative.String())
+	out.WriteString(")")
+
+	return out.String()
+}
+func (cc *ComparisonChain) expressionNode()      {}
+func (cc *ComparisonChain) TokenLiteral() string { return cc.Token.Literal }
+func (cc *ComparisonChain) String() string {
+	var out bytes.Buffer
+
+	parts := []string{cc.Operands[0].String()}
+	for i, op := range cc.Operators {
+		parts = append(parts, op, cc.Operands[i+1].String())
+	}
+	out.WriteString("(")
+	out.WriteString(strings.Join(parts, " "))
+	out.WriteString(")")
+
+	return out.String()
+}
+
+func (ce *ConditionalExpression) Pos() token.Position {
+	if ce.Condition != nil {
+		return ce.Condition.Pos()
+	}
+	return ce.Token.Pos
+}
+func (ce *ConditionalExpression) End() token.Position {
+	if ce.Alternative != nil {
+		return ce.Alternative.End()
+	}
+	return ce.Token.End
+}
+func (cc *ComparisonChain) Pos() token.Position { return cc.Operands[0].Pos() }
+func (cc *ComparisonChain) End() token.Position {
+	if last := cc.Operands[len(cc.Operands)-1]; last != nil {
+		return last.End()
+	}
+	return cc.Token.End
+}
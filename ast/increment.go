@@ -0,0 +1,45 @@
+package ast
+
+import (
+	"bytes"
+	"interpreter/token"
+)
+
+// ++x --x x++ x-- ，Postfix为true时写在目标之后，表达式的值是修改前的值
+type IncrementExpression struct {
+	Token    token.Token
+	Operator string
+	Target   Expression
+	Postfix  bool
+}
+
+func (ie *IncrementExpression) expressionNode()      {}
+func (ie *IncrementExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *IncrementExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	if !ie.Postfix {
+		out.WriteString(ie.Operator)
+	}
+	out.WriteString(ie.Target.String())
+	if ie.Postfix {
+		out.WriteString(ie.Operator)
+	}
+	out.WriteString(")")
+
+	return out.String()
+}
+
+func (ie *IncrementExpression) Pos() token.Position {
+	if ie.Postfix && ie.Target != nil {
+		return ie.Target.Pos()
+	}
+	return ie.Token.Pos
+}
+func (ie *IncrementExpression) End() token.Position {
+	if !ie.Postfix && ie.Target != nil {
+		return ie.Target.End()
+	}
+	return ie.Token.End
+}
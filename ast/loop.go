@@ -0,0 +1,87 @@
+package ast
+
+import (
+	"bytes"
+	"interpreter/token"
+	"strings"
+)
+
+// while (cond) { body }
+type WhileStatement struct {
+	Token     token.Token
+	Condition Expression
+	Body      *BlockStatement
+}
+
+/*
+for (x in iterable) { body } 或 for (a, b in iterable) { body }
+Variables中为一个或两个循环变量
+*/
+type ForStatement struct {
+	Token     token.Token
+	Variables []*Identifier
+	Iterable  Expression
+	Body      *BlockStatement
+}
+type BreakStatement struct {
+	Token token.Token
+}
+type ContinueStatement struct {
+	Token token.Token
+}
+
+func (ws *WhileStatement) StatementNode()       {}
+func (ws *WhileStatement) TokenLiteral() string { return ws.Token.Literal }
+func (ws *WhileStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("while")
+	out.WriteString(ws.Condition.String())
+	out.WriteString(" ")
+	out.WriteString(ws.Body.String())
+
+	return out.String()
+}
+func (fs *ForStatement) StatementNode()       {}
+func (fs *ForStatement) TokenLiteral() string { return fs.Token.Literal }
+func (fs *ForStatement) String() string {
+	var out bytes.Buffer
+
+	variables := []string{}
+	for _, v := range fs.Variables {
+		variables = append(variables, v.String())
+	}
+	out.WriteString("for(")
+	out.WriteString(strings.Join(variables, ", "))
+	out.WriteString(" in ")
+	out.WriteString(fs.Iterable.String())
+	out.WriteString(") ")
+	out.WriteString(fs.Body.String())
+
+	return out.String()
+}
+func (bs *BreakStatement) StatementNode()          {}
+func (bs *BreakStatement) TokenLiteral() string    { return bs.Token.Literal }
+func (bs *BreakStatement) String() string          { return bs.Token.Literal + ";" }
+func (cs *ContinueStatement) StatementNode()       {}
+func (cs *ContinueStatement) TokenLiteral() string { return cs.Token.Literal }
+func (cs *ContinueStatement) String() string       { return cs.Token.Literal + ";" }
+
+func (ws *WhileStatement) Pos() token.Position { return ws.Token.Pos }
+func (ws *WhileStatement) End() token.Position {
+	if ws.Body != nil {
+		return ws.Body.End()
+	}
+	return ws.Token.End
+}
+func (fs *ForStatement) Pos() token.Position { return fs.Token.Pos }
+func (fs *ForStatement) End() token.Position {
+	if fs.Body != nil {
+		return fs.Body.End()
+	}
+	return fs.Token.End
+}
+func (bs *BreakStatement) Pos() token.Position    { return bs.Token.Pos }
+func (bs *BreakStatement) End() token.Position    { return bs.Token.End }
+func (cs *ContinueStatement) Pos() token.Position { return cs.Token.Pos }
+func (cs *ContinueStatement) End() token.Position { return cs.Token.End }
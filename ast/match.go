@@ -0,0 +1,64 @@
+package ast
+
+import (
+	"bytes"
+	"interpreter/token"
+	"strings"
+)
+
+/*
+match (subject) { pattern => body, pattern if guard => body, ... }
+依次尝试每个分支，第一个模式匹配且guard为真的分支的body就是整个表达式的值
+*/
+type MatchExpression struct {
+	Token   token.Token
+	Subject Expression
+	Arms    []*MatchArm
+	RBrace  token.Token
+}
+
+// body为单个表达式时，与箭头函数一样包装为只有一条语句的语句块
+type MatchArm struct {
+	Pattern Expression
+	Guard   Expression //没有guard时为nil
+	Body    *BlockStatement
+}
+
+func (me *MatchExpression) expressionNode()      {}
+func (me *MatchExpression) TokenLiteral() string { return me.Token.Literal }
+func (me *MatchExpression) String() string {
+	var out bytes.Buffer
+
+	arms := []string{}
+	for _, arm := range me.Arms {
+		arms = append(arms, arm.String())
+	}
+	out.WriteString("match")
+	out.WriteString(me.Subject.String())
+	out.WriteString(" {")
+	out.WriteString(strings.Join(arms, ", "))
+	out.WriteString("}")
+
+	return out.String()
+}
+func (ma *MatchArm) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(ma.Pattern.String())
+	if ma.Guard != nil {
+		out.WriteString(" if ")
+		out.WriteString(ma.Guard.String())
+	}
+	out.WriteString(" => ")
+	out.WriteString(ma.Body.String())
+
+	return out.String()
+}
+
+func (me *MatchExpression) Pos() token.Position { return me.Token.Pos }
+func (me *MatchExpression) End() token.Position {
+	if me.RBrace.End.IsValid() {
+		return me.RBrace.End
+	}
+	return me.Token.End
+}
@@ -0,0 +1,91 @@
+package ast
+
+import (
+	"bytes"
+	"interpreter/token"
+	"strings"
+)
+
+/*
+解构模式，用于 let [a, b, ...rest] = xs 和 let {name, age: years} = person
+模式中的每一项是 *Identifier 或者嵌套的 *ArrayPattern 、 *HashPattern
+match表达式中的模式还可以包含字面量，并可以用不带名字的 ... 表示忽略剩余部分
+*/
+type ArrayPattern struct {
+	Token    token.Token
+	Elements []Expression
+	Rest     *Identifier //...rest ，没有时为nil
+	Open     bool        //以不带名字的 ... 结尾
+	RBracket token.Token
+}
+type HashPattern struct {
+	Token  token.Token
+	Fields []*HashPatternField
+	Rest   *Identifier
+	Open   bool
+	RBrace token.Token
+}
+
+// {age: years} 中Key为age，Value为years； {name} 中Value就是与Key同名的标识符
+type HashPatternField struct {
+	Key   string
+	Value Expression
+}
+
+func (ap *ArrayPattern) expressionNode()      {}
+func (ap *ArrayPattern) TokenLiteral() string { return ap.Token.Literal }
+func (ap *ArrayPattern) String() string {
+	var out bytes.Buffer
+
+	elements := []string{}
+	for _, el := range ap.Elements {
+		elements = append(elements, el.String())
+	}
+	if ap.Rest != nil {
+		elements = append(elements, "..."+ap.Rest.String())
+	} else if ap.Open {
+		elements = append(elements, "...")
+	}
+	out.WriteString("[")
+	out.WriteString(strings.Join(elements, ", "))
+	out.WriteString("]")
+	return out.String()
+}
+func (hp *HashPattern) expressionNode()      {}
+func (hp *HashPattern) TokenLiteral() string { return hp.Token.Literal }
+func (hp *HashPattern) String() string {
+	var out bytes.Buffer
+
+	fields := []string{}
+	for _, field := range hp.Fields {
+		if ident, ok := field.Value.(*Identifier); ok && ident.Value == field.Key {
+			fields = append(fields, field.Key)
+		} else {
+			fields = append(fields, field.Key+": "+field.Value.String())
+		}
+	}
+	if hp.Rest != nil {
+		fields = append(fields, "..."+hp.Rest.String())
+	} else if hp.Open {
+		fields = append(fields, "...")
+	}
+	out.WriteString("{")
+	out.WriteString(strings.Join(fields, ", "))
+	out.WriteString("}")
+	return out.String()
+}
+
+func (ap *ArrayPattern) Pos() token.Position { return ap.Token.Pos }
+func (ap *ArrayPattern) End() token.Position {
+	if ap.RBracket.End.IsValid() {
+		return ap.RBracket.End
+	}
+	return ap.Token.End
+}
+func (hp *HashPattern) Pos() token.Position { return hp.Token.Pos }
+func (hp *HashPattern) End() token.Position {
+	if hp.RBrace.End.IsValid() {
+		return hp.RBrace.End
+	}
+	return hp.Token.End
+}
@@ -0,0 +1,198 @@
+package evaluator
+
+import (
+	"fmt"
+	"interpreter/ast"
+	"interpreter/object"
+)
+
+// 复合赋值运算符及其对应的二元运算符
+var compoundOperators = map[string]string{
+	"+=":  "+",
+	"-=":  "-",
+	"*=":  "*",
+	"/=":  "/",
+	"%=":  "%",
+	"**=": "**",
+	"&=":  "&",
+	"|=":  "|",
+	"^=":  "^",
+	"<<=": "<<",
+	">>=": ">>",
+}
+
+/*
+赋值表达式，返回赋值后的值
+目标可以是已定义的变量、数组下标、哈希表的key以及哈希表的字段 h.name
+a[f()] += 1 中 a 和 f() 都只求值一次
+
+数组和哈希表是引用语义：let b = a 之后 b 和 a 是同一个集合，
+通过 b[0] = 1 修改后 a[0] 也会改变，a[i]["x"] = v 修改的也是 a 中保存的那个哈希表
+*/
+func evalAssignExpression(node *ast.InfixExpression, env *object.Environment) object.Object {
+	operator, compound := compoundOperators[node.Operator]
+
+	_, value := evalAssign(node.Left, compound, func(current object.Object) object.Object {
+		value := Eval(node.Right, env)
+		if isAbrupt(value) || !compound {
+			return value
+		}
+		return evalInfixExpression(operator, current, value)
+	}, env)
+	return value
+}
+
+// ++x 的值是修改后的值，x++ 的值是修改前的值，目标与复合赋值一样只求值一次
+func evalIncrementExpression(node *ast.IncrementExpression, env *object.Environment) object.Object {
+	old, value := evalAssign(node.Target, true, func(current object.Object) object.Object {
+		if !isNumber(current) {
+			if node.Postfix {
+				return &object.ErrorType{Message: fmt.Sprintf("unknown operator: %s%s", current.Type(), node.Operator)}
+			}
+			return &object.ErrorType{Message: fmt.Sprintf("unknown operator: %s%s", node.Operator, current.Type())}
+		}
+		return evalInfixExpression(node.Operator[:1], current, &object.Interger{Value: 1})
+	}, env)
+	if isAbrupt(value) || !node.Postfix {
+		return value
+	}
+	return old
+}
+
+/*
+对赋值目标求值一次，再用update根据当前值计算新的值并写回，返回修改前和修改后的值
+read为false时不读取当前值，因此 h["new"] = 1 可以插入不存在的key，此时old为nil
+*/
+func evalAssign(target ast.Expression, read bool, update func(current object.Object) object.Object, env *object.Environment) (old, value object.Object) {
+	switch target := target.(type) {
+	case *ast.Identifier:
+		current, ok := env.Get(target.Value)
+		if !ok {
+			return nil, &object.ErrorType{Message: fmt.Sprintf("identifier not found: %s", target.Value)}
+		}
+		if env.IsConst(target.Value) {
+			return nil, &object.ErrorType{Message: fmt.Sprintf("cannot assign to constant %s", target.Value)}
+		}
+		value := update(current)
+		if isAbrupt(value) {
+			return nil, value
+		}
+		env.Assign(target.Value, value)
+		return current, value
+	case *ast.IndexExpression:
+		if target.Optional {
+			return nil, optionalAssignError(target)
+		}
+		collection := Eval(target.Left, env)
+		if isAbrupt(collection) {
+			return nil, collection
+		}
+		index := Eval(target.Index, env)
+		if isAbrupt(index) {
+			return nil, index
+		}
+		return evalIndexAssign(collection, index, read, update)
+	case *ast.PropertyExpression:
+		if target.Optional {
+			return nil, optionalAssignError(target)
+		}
+		collection := Eval(target.Object, env)
+		if isAbrupt(collection) {
+			return nil, collection
+		}
+		if collection.Type() != object.HASH_OBJ {
+			return nil, &object.ErrorType{Message: fmt.Sprintf("cannot assign to property %s of %s", target.Property.Value, collection.Type())}
+		}
+		return evalIndexAssign(collection, &object.String{Value: target.Property.Value}, read, update)
+	}
+	return nil, &object.ErrorType{Message: fmt.Sprintf("unknown Assign for %s", target.String())}
+}
+
+// a?.b = v 在a为null时无处可赋值，因此可选链不能作为赋值目标
+func optionalAssignError(target ast.Expression) *object.ErrorType {
+	return &object.ErrorType{Message: fmt.Sprintf("cannot assign to optional chain: %s", target.String())}
+}
+
+// 对 collection[index] 赋值，collection和index已经求值
+func evalIndexAssign(collection, index object.Object, read bool, update func(current object.Object) object.Object) (old, value object.Object) {
+	var current object.Object
+	if read {
+		current = getIndex(collection, index)
+		if current.Type() == object.ERROR_OBJ {
+			return nil, current
+		}
+	}
+	value = update(current)
+	if isAbrupt(value) {
+		return nil, value
+	}
+	if err := setIndex(collection, index, value); err != nil {
+		return nil, err
+	}
+	return current, value
+}
+
+// 读取已存在的元素，数组可以使用负数下标，越界或key不存在时返回错误
+func getIndex(collection, index object.Object) object.Object {
+	switch collection := collection.(type) {
+	case *object.Array:
+		idx, ok := index.(*object.Interger)
+		if !ok {
+			return &object.ErrorType{Message: fmt.Sprintf("index:%s is not INTEGER", index.Inspect())}
+		}
+		i, ok := normalizeIndex(idx.Value, len(collection.Elements))
+		if !ok {
+			return indexOutOfRangeError(idx.Value, len(collection.Elements))
+		}
+		return collection.Elements[i]
+	case *object.Hash:
+		hashable, ok := index.(object.Hashable)
+		if !ok {
+			return unusableHashKeyError(index)
+		}
+		pair, ok := collection.Pairs[hashable.HashKey()]
+		if !ok {
+			return &object.ErrorType{Message: fmt.Sprintf("key not found: %s", index.Inspect())}
+		}
+		return pair.Value
+	}
+	return &object.ErrorType{Message: fmt.Sprintf("index operator not supported: %s", collection.Type())}
+}
+
+// 修改数组或哈希表中的元素，数组可以使用负数下标但不能越界，哈希表不存在的key会被插入
+func setIndex(collection, index, value object.Object) *object.ErrorType {
+	switch collection := collection.(type) {
+	case *object.Array:
+		if collection.Frozen {
+			return frozenError(collection)
+		}
+		idx, ok := index.(*object.Interger)
+		if !ok {
+			return &object.ErrorType{Message: fmt.Sprintf("index:%s is not INTEGER", index.Inspect())}
+		}
+		i, ok := normalizeIndex(idx.Value, len(collection.Elements))
+		if !ok {
+			return indexOutOfRangeError(idx.Value, len(collection.Elements))
+		}
+		collection.Elements[i] = value
+		return nil
+	case *object.Hash:
+		if collection.Frozen {
+			return frozenError(collection)
+		}
+		hashable, ok := index.(object.Hashable)
+		if !ok {
+			return unusableHashKeyError(index)
+		}
+		collection.Pairs[hashable.HashKey()] = object.HashPair{Key: index, Value: value}
+		return nil
+	}
+	return &object.ErrorType{Message: fmt.Sprintf("index assignment not supported: %s", collection.Type())}
+}
+
+func indexOutOfRangeError(index int64, length int) *object.ErrorType {
+	return &object.ErrorType{Message: fmt.Sprintf("index out of range: %d with length %d", index, length)}
+}
+func unusableHashKeyError(key object.Object) *object.ErrorType {
+	return &object.ErrorType{Message: fmt.Sprintf("unusable as hash key: %s", key.Type())}
+}
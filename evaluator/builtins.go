@@ -3,8 +3,15 @@ package evaluator
 import (
 	"fmt"
 	"interpreter/object"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
 )
 
+// puts的输出位置
+var output io.Writer = os.Stdout
+
 var builtins = map[string]*object.Builtin{
 	"len": &object.Builtin{
 		Fn: func(args ...object.Object) object.Object {
@@ -13,7 +20,7 @@ var builtins = map[string]*object.Builtin{
 			}
 			switch arg := args[0].(type) {
 			case *object.String:
-				return &object.Interger{Value: int64(len(arg.Value))}
+				return &object.Interger{Value: int64(utf8.RuneCountInString(arg.Value))}
 			case *object.Array:
 				return &object.Interger{Value: int64(len(arg.Elements))}
 			default:
@@ -89,13 +96,66 @@ var builtins = map[string]*object.Builtin{
 			}
 		},
 	},
+	//puts(a, b, sep: ", ") ，sep为参数之间的分隔符，默认每个参数单独一行
 	"puts": &object.Builtin{
-		Fn: func(args ...object.Object) object.Object {
+		KeywordFn: func(args []object.Object, kwargs map[string]object.Object) object.Object {
+			sep := "\n"
+			for name, value := range kwargs {
+				if name != "sep" {
+					return &object.ErrorType{Message: fmt.Sprintf("unknown keyword argument to `puts`: %s", name)}
+				}
+				str, ok := value.(*object.String)
+				if !ok {
+					return &object.ErrorType{Message: fmt.Sprintf("argument `sep` to `puts` must be STRING, got %s", value.Type())}
+				}
+				sep = str.Value
+			}
+			if len(args) == 0 {
+				return NULL
+			}
+			parts := []string{}
 			for _, arg := range args {
-				fmt.Println(arg.Inspect())
+				parts = append(parts, arg.Inspect())
 			}
+			fmt.Fprintln(output, strings.Join(parts, sep))
 
 			return NULL
 		},
 	},
+	//freeze(value) 使数组和哈希表及其中嵌套的数组和哈希表都不能再被修改，返回value本身
+	"freeze": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return &object.ErrorType{Message: fmt.Sprintf("wrong number of arguments. got=%d, want=1", len(args))}
+			}
+			freeze(args[0])
+			return args[0]
+		},
+	},
+}
+
+// 递归地冻结数组和哈希表，已冻结的不再进入，因此引用自身的集合也能结束
+func freeze(obj object.Object) {
+	switch obj := obj.(type) {
+	case *object.Array:
+		if obj.Frozen {
+			return
+		}
+		obj.Frozen = true
+		for _, el := range obj.Elements {
+			freeze(el)
+		}
+	case *object.Hash:
+		if obj.Frozen {
+			return
+		}
+		obj.Frozen = true
+		for _, pair := range obj.Pairs {
+			freeze(pair.Value)
+		}
+	}
+}
+
+func frozenError(obj object.Object) *object.ErrorType {
+	return &object.ErrorType{Message: fmt.Sprintf("cannot modify frozen %s", obj.Type())}
 }
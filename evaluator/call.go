@@ -0,0 +1,196 @@
+package evaluator
+
+import (
+	"fmt"
+	"interpreter/ast"
+	"interpreter/object"
+	"sort"
+)
+
+// 调用已经求值的function，参数在这里求值
+func evalCallExpression(node *ast.CallExpression, function object.Object, env *object.Environment) object.Object {
+	args, err := evalExpressions(node.Arguments, env)
+	if err != nil {
+		return err
+	}
+	var kwargs map[string]object.Object
+	if len(node.Keywords) > 0 {
+		kwargs = make(map[string]object.Object, len(node.Keywords))
+		for _, kw := range node.Keywords {
+			value := Eval(kw.Value, env)
+			if isAbrupt(value) {
+				return value
+			}
+			kwargs[kw.Name.Value] = value
+		}
+	}
+	return applyFunction(function, args, kwargs)
+}
+
+func applyFunction(function object.Object, args []object.Object, kwargs map[string]object.Object) object.Object {
+	switch fn := function.(type) {
+	case *object.Function:
+		fnEnv, err := bindArguments(fn, args, kwargs)
+		if err != nil {
+			return err
+		}
+		result := Eval(fn.Body, fnEnv)
+		if rt, ok := result.(*object.ReturnType); ok {
+			return rt.Value
+		}
+		//函数体为空
+		if result == nil {
+			return NULL
+		}
+		return result
+	case *object.Builtin:
+		if fn.KeywordFn != nil {
+			return fn.KeywordFn(args, kwargs)
+		}
+		if len(kwargs) > 0 {
+			return &object.ErrorType{Message: "builtin function does not accept keyword arguments"}
+		}
+		return fn.Fn(args...)
+	}
+	return &object.ErrorType{Message: fmt.Sprintf("not a function: %s", function.Type())}
+}
+
+/*
+在函数定义时的作用域之上创建新的作用域，并绑定参数
+位置参数依次绑定，关键字参数按参数名绑定，同一个参数不能既按位置又按名字传入
+缺少的参数使用默认值，默认值在调用时求值，可以引用前面的参数，例如 fn(a, b = a * 2)
+多余的位置参数收集到 ...rest 中，没有 ...rest 时参数过多是错误
+*/
+func bindArguments(fn *object.Function, args []object.Object, kwargs map[string]object.Object) (*object.Environment, *object.ErrorType) {
+	required := 0
+	for i := range fn.Parameters {
+		if i >= len(fn.Defaults) || fn.Defaults[i] == nil {
+			required = i + 1
+		}
+	}
+	if (len(kwargs) == 0 && len(args) < required) || (fn.Rest == nil && len(args) > len(fn.Parameters)) {
+		return nil, wrongArgumentCountError(fn, required, len(args))
+	}
+	//按名字排序后检查，使出错时报告的参数是确定的
+	names := make([]string, 0, len(kwargs))
+	for name := range kwargs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		index := -1
+		for i, param := range fn.Parameters {
+			if param.Value == name {
+				index = i
+			}
+		}
+		if index < 0 {
+			return nil, &object.ErrorType{Message: fmt.Sprintf("unknown keyword argument: %s", name)}
+		}
+		if index < len(args) {
+			return nil, &object.ErrorType{Message: fmt.Sprintf("multiple values for argument: %s", name)}
+		}
+	}
+
+	fnEnv := object.NewEnvironment(fn.Environment)
+	for i, param := range fn.Parameters {
+		if i < len(args) {
+			fnEnv.Set(param.Value, args[i])
+			continue
+		}
+		if value, ok := kwargs[param.Value]; ok {
+			fnEnv.Set(param.Value, value)
+			continue
+		}
+		if i >= len(fn.Defaults) || fn.Defaults[i] == nil {
+			return nil, &object.ErrorType{Message: fmt.Sprintf("missing argument: %s", param.Value)}
+		}
+		value := Eval(fn.Defaults[i], fnEnv)
+		if err, ok := value.(*object.ErrorType); ok {
+			return nil, err
+		}
+		//默认值在函数内求值，其中的return、break、continue不能跳出函数
+		if isAbrupt(value) {
+			return nil, &object.ErrorType{Message: fmt.Sprintf("cannot use %s in default value of %s", value.Type(), param.Value)}
+		}
+		fnEnv.Set(param.Value, value)
+	}
+	if fn.Rest != nil {
+		rest := []object.Object{}
+		if len(args) > len(fn.Parameters) {
+			rest = append(rest, args[len(fn.Parameters):]...)
+		}
+		fnEnv.Set(fn.Rest.Value, &object.Array{Elements: rest})
+	}
+	return fnEnv, nil
+}
+
+func wrongArgumentCountError(fn *object.Function, required, got int) *object.ErrorType {
+	want := fmt.Sprintf("%d", required)
+	if fn.Rest != nil {
+		want = fmt.Sprintf("at least %d", required)
+	} else if required != len(fn.Parameters) {
+		want = fmt.Sprintf("%d to %d", required, len(fn.Parameters))
+	}
+	return &object.ErrorType{Message: fmt.Sprintf("wrong number of arguments. got=%d, want=%s", got, want)}
+}
+
+// 依次对表达式求值，...xs 展开为数组xs中的各个元素，遇到错误或return、break、continue时原样返回
+func evalExpressions(exps []ast.Expression, env *object.Environment) ([]object.Object, object.Object) {
+	result := []object.Object{}
+	for _, exp := range exps {
+		if spread, ok := exp.(*ast.SpreadExpression); ok {
+			value := Eval(spread.Value, env)
+			if isAbrupt(value) {
+				return nil, value
+			}
+			arr, ok := value.(*object.Array)
+			if !ok {
+				return nil, &object.ErrorType{Message: fmt.Sprintf("cannot spread %s, expected ARRAY", value.Type()), Pos: spread.Pos()}
+			}
+			result = append(result, arr.Elements...)
+			continue
+		}
+		value := Eval(exp, env)
+		if isAbrupt(value) {
+			return nil, value
+		}
+		result = append(result, value)
+	}
+	return result, nil
+}
+
+// 按源码顺序对键值对和 ...spread 求值，后出现的key覆盖先出现的
+func evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Object {
+	mp := &object.Hash{Pairs: make(map[object.HashKey]object.HashPair)}
+	for _, key := range node.Order {
+		if spread, ok := key.(*ast.SpreadExpression); ok {
+			value := Eval(spread.Value, env)
+			if isAbrupt(value) {
+				return value
+			}
+			hash, ok := value.(*object.Hash)
+			if !ok {
+				return &object.ErrorType{Message: fmt.Sprintf("cannot spread %s, expected HASH", value.Type()), Pos: spread.Pos()}
+			}
+			for hashKey, pair := range hash.Pairs {
+				mp.Pairs[hashKey] = pair
+			}
+			continue
+		}
+		k := Eval(key, env)
+		if isAbrupt(k) {
+			return k
+		}
+		v := Eval(node.Pairs[key], env)
+		if isAbrupt(v) {
+			return v
+		}
+		hashable, ok := k.(object.Hashable)
+		if !ok {
+			return unusableHashKeyError(k)
+		}
+		mp.Pairs[hashable.HashKey()] = object.HashPair{Key: k, Value: v}
+	}
+	return mp
+}
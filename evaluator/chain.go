@@ -0,0 +1,57 @@
+package evaluator
+
+import (
+	"interpreter/ast"
+	"interpreter/object"
+)
+
+/*
+对字段访问、下标、切片和调用组成的链中的一环求值，例如 a?.b.c(1)[0]
+某一环的 ?. 或 ?[ 左侧为null时，这一环和它之后的整个链的结果都是null，short为true表示发生了这种短路
+因此 user?.address.city 在user为null时得到null，而不是在访问 null.city 时出错
+*/
+func evalChainLink(node ast.Expression, env *object.Environment) (result object.Object, short bool) {
+	switch node := node.(type) {
+	case *ast.PropertyExpression:
+		receiver, short := evalChain(node.Object, node.Optional, env)
+		if short || isAbrupt(receiver) {
+			return receiver, short
+		}
+		return evalPropertyExpression(receiver, node.Property.Value), false
+	case *ast.IndexExpression:
+		left, short := evalChain(node.Left, node.Optional, env)
+		if short || isAbrupt(left) {
+			return left, short
+		}
+		index := Eval(node.Index, env)
+		if isAbrupt(index) {
+			return index, false
+		}
+		return evalIndexExpression(left, index), false
+	case *ast.SliceExpression:
+		left, short := evalChain(node.Left, node.Optional, env)
+		if short || isAbrupt(left) {
+			return left, short
+		}
+		return evalSliceExpression(node, left, env), false
+	case *ast.CallExpression:
+		function, short := evalChain(node.Function, node.Optional, env)
+		if short || isAbrupt(function) {
+			return function, short
+		}
+		return evalCallExpression(node, function, env), false
+	}
+	return Eval(node, env), false
+}
+
+// 对链中某一环左侧的部分求值，optional为true且结果为null时短路
+func evalChain(node ast.Expression, optional bool, env *object.Environment) (object.Object, bool) {
+	result, short := evalChainLink(node, env)
+	if err, ok := result.(*object.ErrorType); ok && !err.Pos.IsValid() {
+		err.Pos = node.Pos()
+	}
+	if short || (optional && result == NULL) {
+		return NULL, true
+	}
+	return result, false
+}
@@ -0,0 +1,37 @@
+package evaluator
+
+import (
+	"interpreter/ast"
+	"interpreter/object"
+)
+
+func evalConditionalExpression(node *ast.ConditionalExpression, env *object.Environment) object.Object {
+	cond := Eval(node.Condition, env)
+	if isAbrupt(cond) {
+		return cond
+	}
+	if isTruthy(cond) {
+		return Eval(node.Consequence, env)
+	}
+	return Eval(node.Alternative, env)
+}
+
+// 从左到右依次比较相邻的两个操作数，每个操作数只求值一次，某次比较为假时后面的操作数不再求值
+func evalComparisonChain(node *ast.ComparisonChain, env *object.Environment) object.Object {
+	left := Eval(node.Operands[0], env)
+	if isAbrupt(left) {
+		return left
+	}
+	for i, op := range node.Operators {
+		right := Eval(node.Operands[i+1], env)
+		if isAbrupt(right) {
+			return right
+		}
+		result := evalInfixExpression(op, left, right)
+		if result.Type() == object.ERROR_OBJ || !isTruthy(result) {
+			return result
+		}
+		left = right
+	}
+	return TRUE
+}
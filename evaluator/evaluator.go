@@ -1,148 +1,87 @@
 package evaluator
 
 import (
+	"bytes"
 	"fmt"
 	"interpreter/ast"
 	"interpreter/object"
+	"math"
 )
 
 var (
 	NULL  = &object.NULL{}
 	TRUE  = &object.BooleanType{Value: true}
 	FALSE = &object.BooleanType{Value: false}
+
+	BREAK    = &object.BreakType{}
+	CONTINUE = &object.ContinueType{}
 )
 
+// Eval 对节点求值，若产生的错误还没有位置信息，则记录为当前节点的位置
+// 由于是自底向上返回，错误记录的是最先产生它的节点的位置
 func Eval(node ast.Node, env *object.Environment) object.Object {
+	result := eval(node, env)
+	if err, ok := result.(*object.ErrorType); ok && !err.Pos.IsValid() {
+		err.Pos = node.Pos()
+	}
+	return result
+}
+func eval(node ast.Node, env *object.Environment) object.Object {
 	//fmt.Appendln([]byte(node.String()))
 	switch node := node.(type) {
 	case *ast.HashLiteral:
-		mp := &object.Hash{Pairs: make(map[object.HashKey]object.HashPair)}
-		for key, value := range node.Pairs {
-			k := Eval(key, env)
-			v := Eval(value, env)
-			hp := object.HashPair{Key: k, Value: v}
-			var hk object.HashKey
-			switch k := k.(type) {
-			case *object.Interger:
-				hk = k.HashKey()
-			case *object.String:
-				hk = k.HashKey()
-			case *object.BooleanType:
-				hk = k.HashKey()
-			}
-			mp.Pairs[hk] = hp
-		}
-		return mp
-	case *ast.IndexExpression:
-		switch left := Eval(node.Left, env).(type) {
-		case *object.Array:
-			el := Eval(node.Index, env)
-			idx, ok := el.(*object.Interger)
-			if !ok {
-				return &object.ErrorType{Message: fmt.Sprintf("index:%s is not INTEGER", node.Index.TokenLiteral())}
-			}
-			if int(idx.Value) >= len(left.Elements) || idx.Value < 0 {
-				return NULL
-			}
-			return left.Elements[idx.Value]
-		case *object.Hash:
-			el := Eval(node.Index, env)
-			var key object.HashKey
-			switch el := el.(type) {
-			case *object.BooleanType:
-				key = el.HashKey()
-			case *object.String:
-				key = el.HashKey()
-			case *object.Interger:
-				key = el.HashKey()
-			}
-			value, ok := left.Pairs[key]
-			if !ok {
-				return NULL
-			}
-			return value.Value
-		}
+		return evalHashLiteral(node, env)
+	case *ast.IndexExpression, *ast.SliceExpression, *ast.PropertyExpression, *ast.CallExpression:
+		result, _ := evalChainLink(node.(ast.Expression), env)
+		return result
 
 	case *ast.ArrayLiteral:
-		var elements []object.Object
-		for _, ele := range node.Elements {
-			elements = append(elements, Eval(ele, env))
+		elements, err := evalExpressions(node.Elements, env)
+		if err != nil {
+			return err
 		}
 		return &object.Array{Elements: elements}
 
 	case *ast.StringLiteral:
 		return &object.String{Value: node.Value}
-	case *ast.CallExpression:
-		switch Callfn := Eval(node.Function, env).(type) {
-		case *object.Function:
-			if node.Function.String() != node.Function.TokenLiteral() {
-				Newenv := object.NewEnvironment(env)
-				fn := Eval(node.Function, Newenv)
-				fun, _ := fn.(*object.Function)
-				if len(fun.Parameters) != len(node.Arguments) {
-					return &object.ErrorType{Message: fmt.Sprintf("want %d Arguments get=%d", len(fun.Parameters), len(node.Arguments))}
-				}
-				for i, arg := range node.Arguments {
-					env.Set(fun.Parameters[i].Value, Eval(arg, env))
-				}
-				res := Eval(fun.Body, Newenv)
-				if rt, ok := res.(*object.ReturnType); ok {
-					return rt.Value
-				}
-				return res
+	case *ast.InterpolatedString:
+		var out bytes.Buffer
+		for _, part := range node.Parts {
+			value := Eval(part, env)
+			if isAbrupt(value) {
+				return value
 			}
-			fn, _ := env.Get(node.Function.TokenLiteral())
-			fun := fn.(*object.Function)
-			if len(fun.Parameters) != len(node.Arguments) {
-				return &object.ErrorType{Message: fmt.Sprintf("want %d Arguments get=%d", len(fun.Parameters), len(node.Arguments))}
-			}
-			var fnEnv *object.Environment
-			if !fun.Environment.IsNull() {
-				fnEnv = fun.Environment
-			} else {
-				fnEnv = object.NewEnvironment(env)
-			}
-
-			for i, arg := range node.Arguments {
-				fnEnv.Set(fun.Parameters[i].Value, Eval(arg, env))
-			}
-			res := Eval(fun.Body, fnEnv)
-			if rt, ok := res.(*object.ReturnType); ok {
-				return rt.Value
-			}
-			return res
-		case *object.Builtin:
-
-			var args = []object.Object{}
-			for i := range node.Arguments {
-				arg := Eval(node.Arguments[i], env)
-				args = append(args, arg)
-			}
-			return Callfn.Fn(args...)
+			out.WriteString(value.Inspect())
 		}
+		return &object.String{Value: out.String()}
 	case *ast.FunctionLiteral:
 		res := &object.Function{Environment: env}
 		res.Parameters = node.Parameters
+		res.Defaults = node.Defaults
+		res.Rest = node.Rest
 		res.Body = node.Body
 		return res
 	case *ast.LetStatement:
-		name := node.Name.Value
 		value := Eval(node.Value, env)
-		if value.Type() == object.FUNCTION_OBJ {
-			value := value.(*object.Function)
-			if para, ok := node.Value.(*ast.CallExpression); ok {
-				gt, _ := env.Get(para.Function.TokenLiteral())
-				fn := gt.(*object.Function)
-				for i, pr := range para.Arguments {
-					value.Environment.SetArgs(fn.Parameters[i].Value, Eval(pr, env))
-				}
+		if isAbrupt(value) {
+			return value
+		}
+		if node.Pattern != nil {
+			if err := bindPattern(node.Pattern, value, env, node.IsConst()); err != nil {
+				return err
 			}
+			return NULL
+		}
+		if err := declare(env, node.Name, value, node.IsConst()); err != nil {
+			return err
 		}
-		env.Set(name, value)
 		return NULL
 	case *ast.ReturnStatement:
+		if node.ReturnValue == nil {
+			return &object.ReturnType{Value: NULL}
+		}
 		value := Eval(node.ReturnValue, env)
-		if value.Type() == object.ERROR_OBJ {
+		if isAbrupt(value) {
 			return value
 		}
 		return &object.ReturnType{Value: value}
@@ -150,12 +89,22 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		return evalStatements(node.Statements, env)
 	case *ast.IntegerLiteral:
 		return &object.Interger{Value: node.Value}
+	case *ast.FloatLiteral:
+		return &object.Float{Value: node.Value}
 	case *ast.ExpressionStatement:
 		return Eval(node.Expression, env)
 	case *ast.Boolean:
 		return nativeBooleanObject(node.Value)
+	case *ast.NullLiteral:
+		return NULL
+	case *ast.IncrementExpression:
+		return evalIncrementExpression(node, env)
 	case *ast.PrefixExpression:
-		return evalPrefixExpression(node.Operator, Eval(node.Right, env))
+		right := Eval(node.Right, env)
+		if isAbrupt(right) {
+			return right
+		}
+		return evalPrefixExpression(node.Operator, right)
 	case *ast.Identifier:
 		value, ok := env.Get(node.Value)
 		if ok {
@@ -167,37 +116,58 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		return &object.ErrorType{Message: fmt.Sprintf("identifier not found: %s", node.Value)}
 
 	case *ast.InfixExpression:
-		if node.Operator == "=" {
-			if _, ok := node.Left.(*ast.Identifier); !ok {
-				return &object.ErrorType{Message: fmt.Sprintf("unknown Assign for %s", node.Left.TokenLiteral())}
-			}
-			if _, ok := env.Get(node.Left.TokenLiteral()); !ok {
-				return &object.ErrorType{Message: fmt.Sprintf("identifier not found: %s", node.Left.TokenLiteral())}
-			}
-			env.Set(node.Left.TokenLiteral(), Eval(node.Right, env))
-			return Eval(node.Left, env)
+		if _, ok := compoundOperators[node.Operator]; ok || node.Operator == "=" {
+			return evalAssignExpression(node, env)
 		}
 		left := Eval(node.Left, env)
 
-		if left.Type() == object.ERROR_OBJ {
+		if isAbrupt(left) {
+			return left
+		}
+		//&& 和 || 短路求值，返回的是操作数本身而不是布尔值
+		if node.Operator == "&&" && !isTruthy(left) {
+			return left
+		}
+		if node.Operator == "||" && isTruthy(left) {
+			return left
+		}
+		//a ?? b 只在a为null时才对b求值
+		if node.Operator == "??" && left != NULL {
 			return left
 		}
 		right := Eval(node.Right, env)
-		if right.Type() == object.ERROR_OBJ {
+		if isAbrupt(right) {
 			return right
 		}
 		return evalInfixExpression(node.Operator, left, right)
 	case *ast.BlockStatement:
 		return evalStatements(node.Statements, env)
+	case *ast.WhileStatement:
+		return evalWhileStatement(node, env)
+	case *ast.ForStatement:
+		return evalForStatement(node, env)
+	case *ast.BreakStatement:
+		return BREAK
+	case *ast.ContinueStatement:
+		return CONTINUE
 	case *ast.IfExpression:
 		cond := Eval(node.Condition, env)
-		if cond != NULL && cond != FALSE {
+		if isAbrupt(cond) {
+			return cond
+		}
+		if isTruthy(cond) {
 			return Eval(node.Consequence, env)
 		}
 		if node.Alternative == nil {
 			return NULL
 		}
 		return Eval(node.Alternative, env)
+	case *ast.MatchExpression:
+		return evalMatchExpression(node, env)
+	case *ast.ConditionalExpression:
+		return evalConditionalExpression(node, env)
+	case *ast.ComparisonChain:
+		return evalComparisonChain(node, env)
 	}
 	return NULL
 }
@@ -207,14 +177,25 @@ func evalPrefixExpression(operator string, right object.Object) object.Object {
 	case "!":
 		return evalBangOperatorExpression(right)
 	case "-":
-		if right.Type() != object.INTEGER_OBJ {
+		if right.Type() != object.INTEGER_OBJ && right.Type() != object.FLOAT_OBJ {
 			return &object.ErrorType{Message: fmt.Sprintf("unknown operator: %s%s", operator, right.Type())}
 		}
 		return evalMinusOperatorExpression(right)
+	case "~":
+		rt, ok := right.(*object.Interger)
+		if !ok {
+			return &object.ErrorType{Message: fmt.Sprintf("unknown operator: %s%s", operator, right.Type())}
+		}
+		return &object.Interger{Value: ^rt.Value}
 	default:
 		return NULL
 	}
 }
+
+// 只有false和null为假，其余值均为真
+func isTruthy(obj object.Object) bool {
+	return obj != NULL && obj != FALSE
+}
 func evalBangOperatorExpression(right object.Object) object.Object {
 	switch right {
 	case TRUE:
@@ -228,18 +209,20 @@ func evalBangOperatorExpression(right object.Object) object.Object {
 	}
 }
 func evalMinusOperatorExpression(right object.Object) object.Object {
-	rt, ok := right.(*object.Interger)
-	if !ok {
-		return NULL
+	switch rt := right.(type) {
+	case *object.Interger:
+		return &object.Interger{Value: (-rt.Value)}
+	case *object.Float:
+		return &object.Float{Value: (-rt.Value)}
 	}
-	return &object.Interger{Value: (-rt.Value)}
+	return NULL
 }
 func evalStatements(stmts []ast.Statement, env *object.Environment) object.Object {
 	var result object.Object
 
 	for _, stmt := range stmts {
 		result = Eval(stmt, env)
-		if result.Type() == object.RETURN_OBJ || result.Type() == object.ERROR_OBJ {
+		if isAbrupt(result) {
 			return result
 		}
 	}
@@ -247,6 +230,19 @@ func evalStatements(stmts []ast.Statement, env *object.Environment) object.Objec
 	return result
 }
 
+/*
+错误以及return、break、continue产生的信号会中断正常的执行流程，它们不是普通的值
+作为值使用时（赋值、参数、运算数、条件等）要原样向外传递，直到被函数调用或循环处理
+例如 let v = if (x == 2) { break } else { x } 会跳出循环，而不是把break赋给v
+*/
+func isAbrupt(obj object.Object) bool {
+	switch obj.Type() {
+	case object.ERROR_OBJ, object.RETURN_OBJ, object.BREAK_OBJ, object.CONTINUE_OBJ:
+		return true
+	}
+	return false
+}
+
 func nativeBooleanObject(input bool) *object.BooleanType {
 	if input {
 		return TRUE
@@ -254,9 +250,16 @@ func nativeBooleanObject(input bool) *object.BooleanType {
 	return FALSE
 }
 func evalInfixExpression(operator string, left, right object.Object) object.Object {
+	//&&、||和??在左侧满足条件时已经短路，走到这里时结果就是右侧的值
+	if operator == "&&" || operator == "||" || operator == "??" {
+		return right
+	}
 	switch {
 	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
 		return evalIntegerInfixExpression(operator, left, right)
+	case isNumber(left) && isNumber(right):
+		//整数与浮点数混合运算时，整数提升为浮点数
+		return evalFloatInfixExpression(operator, toFloat(left), toFloat(right))
 	case left.Type() == object.BOOLEAN_OBJ && right.Type() == object.BOOLEAN_OBJ:
 		if operator == "==" {
 			if left == right {
@@ -276,6 +279,18 @@ func evalInfixExpression(operator string, left, right object.Object) object.Obje
 			return evalStringInfixExpression(operator, left, right)
 		}
 		return &object.ErrorType{Message: fmt.Sprintf("unknown operator: %s %s %s", left.Type(), operator, right.Type())}
+	case left == NULL || right == NULL:
+		//null只与null相等，与其他任何值都不相等
+		if operator == "==" {
+			return nativeBooleanObject(left == right)
+		}
+		if operator == "!=" {
+			return nativeBooleanObject(left != right)
+		}
+		if left.Type() == right.Type() {
+			return &object.ErrorType{Message: fmt.Sprintf("unknown operator: %s %s %s", left.Type(), operator, right.Type())}
+		}
+		return &object.ErrorType{Message: fmt.Sprintf("type mismatch: %s %s %s", left.Type(), operator, right.Type())}
 	default:
 		return &object.ErrorType{Message: fmt.Sprintf("type mismatch: %s %s %s", left.Type(), operator, right.Type())}
 	}
@@ -301,7 +316,37 @@ func evalIntegerInfixExpression(operator string, left, right object.Object) obje
 	case "*":
 		return &object.Interger{Value: l.Value * r.Value}
 	case "/":
+		//整数相除结果仍为整数，向零取整
+		if r.Value == 0 {
+			return &object.ErrorType{Message: "division by zero"}
+		}
 		return &object.Interger{Value: l.Value / r.Value}
+	case "%":
+		//结果的符号与被除数相同
+		if r.Value == 0 {
+			return &object.ErrorType{Message: "division by zero"}
+		}
+		return &object.Interger{Value: l.Value % r.Value}
+	case "**":
+		//指数为负数时结果为浮点数
+		if r.Value < 0 {
+			return &object.Float{Value: math.Pow(float64(l.Value), float64(r.Value))}
+		}
+		return &object.Interger{Value: intPow(l.Value, r.Value)}
+	case "&":
+		return &object.Interger{Value: l.Value & r.Value}
+	case "|":
+		return &object.Interger{Value: l.Value | r.Value}
+	case "^":
+		return &object.Interger{Value: l.Value ^ r.Value}
+	case "<<", ">>":
+		if r.Value < 0 {
+			return &object.ErrorType{Message: fmt.Sprintf("negative shift count: %d", r.Value)}
+		}
+		if operator == "<<" {
+			return &object.Interger{Value: l.Value << r.Value}
+		}
+		return &object.Interger{Value: l.Value >> r.Value}
 	case ">":
 		return returnBool(l.Value > r.Value)
 	case "<":
@@ -318,6 +363,63 @@ func evalIntegerInfixExpression(operator string, left, right object.Object) obje
 		return NULL
 	}
 }
+
+// 浮点数运算遵循IEEE 754，除以0得到Inf或NaN
+func evalFloatInfixExpression(operator string, l, r float64) object.Object {
+	switch operator {
+	case "+":
+		return &object.Float{Value: l + r}
+	case "-":
+		return &object.Float{Value: l - r}
+	case "*":
+		return &object.Float{Value: l * r}
+	case "/":
+		return &object.Float{Value: l / r}
+	case "%":
+		return &object.Float{Value: math.Mod(l, r)}
+	case "**":
+		return &object.Float{Value: math.Pow(l, r)}
+	case ">":
+		return returnBool(l > r)
+	case "<":
+		return returnBool(l < r)
+	case "==":
+		return returnBool(l == r)
+	case "!=":
+		return returnBool(l != r)
+	case "<=":
+		return returnBool(l <= r)
+	case ">=":
+		return returnBool(l >= r)
+	default:
+		return &object.ErrorType{Message: fmt.Sprintf("unknown operator: %s %s %s", object.FLOAT_OBJ, operator, object.FLOAT_OBJ)}
+	}
+}
+
+// 快速幂，溢出时与Go的整数运算一样回绕
+func intPow(base, exp int64) int64 {
+	result := int64(1)
+	for exp > 0 {
+		if exp&1 == 1 {
+			result *= base
+		}
+		base *= base
+		exp >>= 1
+	}
+	return result
+}
+func isNumber(obj object.Object) bool {
+	return obj.Type() == object.INTEGER_OBJ || obj.Type() == object.FLOAT_OBJ
+}
+func toFloat(obj object.Object) float64 {
+	switch obj := obj.(type) {
+	case *object.Interger:
+		return float64(obj.Value)
+	case *object.Float:
+		return obj.Value
+	}
+	return 0
+}
 func returnBool(value bool) *object.BooleanType {
 	if value {
 		return TRUE
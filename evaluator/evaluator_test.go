@@ -1,9 +1,12 @@
 package evaluator
 
 import (
+	"bytes"
 	"interpreter/lexer"
 	"interpreter/object"
 	"interpreter/parser"
+	"os"
+	"strings"
 	"testing"
 )
 
@@ -86,6 +89,10 @@ func TestIfElseStatements(t *testing.T) {
 		{"if (1 > 2) { 10 }", nil},
 		{"if (1 > 2) { 10 } else { 20 }", 20},
 		{"if (1 < 2) { 10 } else { 20 }", 10},
+		{"if (1 > 2) { 10 } else if (2 > 1) { 20 } else { 30 }", 20},
+		{"if (1 > 2) { 10 } else if (2 > 3) { 20 } else { 30 }", 30},
+		{"if (1 > 2) { 10 } else if (2 > 3) { 20 }", nil},
+		{"let x = 3; if (x == 1) { 10 } else if (x == 2) { 20 } else if (x == 3) { 30 } else { 40 }", 30},
 	}
 
 	for _, tt := range tests {
@@ -111,6 +118,7 @@ func TestMinusOperator(t *testing.T) {
 		{"5", 5},
 		{"--5", 5},
 		{"---5", -5},
+		{"-9223372036854775807 - 1", -9223372036854775808},
 	}
 
 	for _, tt := range tests {
@@ -133,13 +141,23 @@ func TestReturnStatements(t *testing.T) {
 			return 1;
 			}
 		`, 10},
+		{"let f = fn(x) { if (x) { return }\n 1 }; f(true)", nil},
+		{"let f = fn(x) { if (x) { return }\n 1 }; f(false)", 1},
 	}
 	for _, tt := range tests {
 		obj := testEval(tt.input)
+		//顶层的return不会被拆开
+		if rt, ok := obj.(*object.ReturnType); ok {
+			obj = rt.Value
+		}
 		switch obj.(type) {
 		case *object.Interger:
 			i64 := int64(tt.expected.(int))
 			testIntergerObject(t, obj, i64)
+		default:
+			if tt.expected != nil || obj != NULL {
+				t.Errorf("input %q: expected=%v,got=%s", tt.input, tt.expected, obj.Inspect())
+			}
 		}
 	}
 }
@@ -390,6 +408,14 @@ func TestArrayIndexExpressions(t *testing.T) {
 		},
 		{
 			"[1, 2, 3][-1]",
+			3,
+		},
+		{
+			"[1, 2, 3][-3]",
+			1,
+		},
+		{
+			"[1, 2, 3][-4]",
 			nil,
 		},
 	}
@@ -577,3 +603,1073 @@ func testBoolean(t *testing.T, obj object.Object, expected bool) bool {
 	}
 	return true
 }
+func TestErrorPosition(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let a = 1;\nlet b = a + c;", "2:13"},
+		{"1;\n  5 + true;", "2:3"},
+		{"\n\nlen(1)", "3:1"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.ErrorType)
+		if !ok {
+			t.Errorf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+			continue
+		}
+		if errObj.Pos.String() != tt.expected {
+			t.Errorf("wrong error position. expected=%s,got=%s", tt.expected, errObj.Pos.String())
+		}
+	}
+}
+func TestUnicodeIdentifiers(t *testing.T) {
+	input := `let 名字 = "小明"; let 长度 = len(名字); 长度;`
+	//len返回的是字符数而不是字节数
+	testIntergerObject(t, testEval(input), 2)
+}
+func TestFloatExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"1.5", 1.5},
+		{"-2.5", -2.5},
+		{"1.5 + 1.5", 3.0},
+		//整数相除向零取整，有一个操作数是浮点数时才得到小数
+		{"7 / 2", 3},
+		{"-7 / 2", -3},
+		{"-7 % 2", -1},
+		{"7 / 2 * 1.0", 3.0},
+		{"7 * 1.0 / 2", 3.5},
+		{"7.0 / 2", 3.5},
+		{"7 / 2.0", 3.5},
+		{"1 + 0.5 * 2", 2.0},
+		{"1e3 - 1", 999.0},
+		{"1.5 < 2", true},
+		{"2 == 2.0", true},
+		{"0.1 + 0.2 != 0.3", true},
+		{"3 >= 3.5", false},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case float64:
+			testFloatObject(t, evaluated, expected)
+		case int:
+			testIntergerObject(t, evaluated, int64(expected))
+		case bool:
+			testBoolean(t, evaluated, expected)
+		}
+	}
+}
+func TestFloatInspect(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"2.0", "2.0"},
+		{"1.5 * 2", "3.0"},
+		{"0.25", "0.25"},
+		{"1e21", "1e+21"},
+	}
+	for _, tt := range tests {
+		if actual := testEval(tt.input).Inspect(); actual != tt.expected {
+			t.Errorf("wrong Inspect. expected=%q,got=%q", tt.expected, actual)
+		}
+	}
+}
+func TestDivisionByZero(t *testing.T) {
+	evaluated := testEval("1 / 0")
+	errObj, ok := evaluated.(*object.ErrorType)
+	if !ok {
+		t.Fatalf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+	}
+	if errObj.Message != "division by zero" {
+		t.Errorf("wrong error message. got=%q", errObj.Message)
+	}
+}
+func testFloatObject(t *testing.T, obj object.Object, expected float64) bool {
+	result, ok := obj.(*object.Float)
+	if !ok {
+		t.Errorf("obj is not *object.Float.got=%T (%+v)", obj, obj)
+		return false
+	}
+
+	if result.Value != expected {
+		t.Errorf("result.Value != %g,got=%g", expected, result.Value)
+		return false
+	}
+	return true
+}
+func TestExtendedOperators(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"7 % 3", 7 % 3},
+		{"-7 % 3", -7 % 3},
+		{"7.5 % 2", 1.5},
+		{"2 ** 10", 1024},
+		{"2 ** 3 ** 2", 512},
+		{"-2 ** 2", -4},
+		{"2 ** -1", 0.5},
+		{"2.0 ** 3", 8.0},
+		{"6 & 3", 2},
+		{"6 | 3", 7},
+		{"6 ^ 3", 5},
+		{"1 << 4", 16},
+		{"-16 >> 2", -4},
+		{"~5", -6},
+		{"5 & 1 == 1", true},
+		{"true && false", false},
+		{"true || false", true},
+		{"1 && 2", 2},
+		{"0 || 2", 0},
+		{"false || 3", 3},
+		{"false && undefinedVariable", false},
+		{"true || undefinedVariable", true},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntergerObject(t, evaluated, int64(expected))
+		case float64:
+			testFloatObject(t, evaluated, expected)
+		case bool:
+			testBoolean(t, evaluated, expected)
+		}
+	}
+}
+func TestExtendedOperatorErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{"5 % 0", "division by zero"},
+		{"1 << -1", "negative shift count: -1"},
+		{"~true", "unknown operator: ~BOOLEAN"},
+		{"1.5 & 1", "unknown operator: FLOAT & FLOAT"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.ErrorType)
+		if !ok {
+			t.Errorf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expectedMessage {
+			t.Errorf("wrong error message. expected=%q, got=%q", tt.expectedMessage, errObj.Message)
+		}
+	}
+}
+func TestAssignExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let a = 1; a = 5; a;", 5},
+		{"let a = 1; let b = 2; a = b = 7; a + b;", 14},
+		{"let a = 1; a += 2; a;", 3},
+		{"let a = 10; a -= 3;", 7},
+		{"let a = 3; a *= 4; a;", 12},
+		{"let a = 9; a /= 2; a;", 4},
+		{"let a = 9; a %= 4; a;", 1},
+		{"let a = 3; a **= 2; a;", 9},
+		{"let a = 6; a &= 3; a;", 2},
+		{"let a = 6; a |= 1; a;", 7},
+		{"let a = 6; a ^= 2; a;", 4},
+		{"let a = 1; a <<= 3; a;", 8},
+		{"let a = 8; a >>= 2; a;", 2},
+		{"let a = 1; a += 0.5; a;", 1.5},
+		{`let s = "a"; s += "b"; s;`, "ab"},
+		{"let arr = [1, 2, 3]; arr[1] += 10; arr[1];", 12},
+		{`let h = {"n": 1}; h["n"] *= 5; h["n"];`, 5},
+		{"let count = 0; let inc = fn() { count += 1; }; inc(); inc(); count;", 2},
+		{"let arr = [0, 0]; let i = 0; let next = fn() { i += 1; i - 1 }; arr[next()] += 5; [arr[0], arr[1], i];", []int64{5, 0, 1}},
+		{"let arr = [1, 2, 3]; arr[0] = 5; arr;", []int64{5, 2, 3}},
+		{"let arr = [1, 2, 3]; arr[2] = arr[1] = 7; arr;", []int64{1, 7, 7}},
+		{`let h = {}; h["k"] = 1; h["k"] = h["k"] + 1; h["k"];`, 2},
+		{`let h = {}; h.name = "bob"; h["name"];`, "bob"},
+		{`let h = {"n": 1}; h.n += 2; h.n;`, 3},
+		{`let grid = [[0, 0], [0, 0]]; grid[1][0] = 4; grid[1];`, []int64{4, 0}},
+		{`let rows = [{"x": 1}]; rows[0]["x"] = 9; rows[0].x;`, 9},
+		{`let users = {"a": {"tags": [1]}}; users.a.tags[0] = 2; users["a"]["tags"];`, []int64{2}},
+		{"let a = [1, 2]; let b = a; b[0] = 9; a;", []int64{9, 2}},
+		{"let a = [1]; let set = fn(arr) { arr[0] = 3 }; set(a); a;", []int64{3}},
+		{"let arr = [1, 2]; arr[-1] += 5; arr[-1] = arr[-1] * 2; arr[1]", 14},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntergerObject(t, evaluated, int64(expected))
+		case float64:
+			testFloatObject(t, evaluated, expected)
+		case string:
+			str, ok := evaluated.(*object.String)
+			if !ok || str.Value != expected {
+				t.Errorf("expected string %q,got=%s", expected, evaluated.Inspect())
+			}
+		case []int64:
+			arr, ok := evaluated.(*object.Array)
+			if !ok || len(arr.Elements) != len(expected) {
+				t.Errorf("expected array %v,got=%s", expected, evaluated.Inspect())
+				continue
+			}
+			for i, el := range arr.Elements {
+				testIntergerObject(t, el, expected[i])
+			}
+		}
+	}
+}
+func TestAssignErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{"b += 1", "identifier not found: b"},
+		{"let a = true; a += 1;", "type mismatch: BOOLEAN + INTEGER"},
+		{"let arr = [1]; arr[3] += 1;", "index out of range: 3 with length 1"},
+		{`let h = {}; h["x"] += 1;`, `key not found: x`},
+		{"1 += 2", "unknown Assign for 1"},
+		{"let arr = [1]; arr[1] = 2;", "index out of range: 1 with length 1"},
+		{"let arr = [1]; arr[-2] = 2;", "index out of range: -2 with length 1"},
+		{`let arr = [1]; arr["0"] = 2;`, "index:0 is not INTEGER"},
+		{`let h = {}; h[[1]] = 2;`, "unusable as hash key: ARRAY"},
+		{`let s = "abc"; s[0] = "x";`, "index assignment not supported: STRING"},
+		{`let arr = [1]; arr.x = 2;`, "cannot assign to property x of ARRAY"},
+		{`let h = {}; h["a"]["b"] = 1;`, "index assignment not supported: NULL"},
+		{"undefined[0] = 1", "identifier not found: undefined"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.ErrorType)
+		if !ok {
+			t.Errorf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expectedMessage {
+			t.Errorf("wrong error message. expected=%q, got=%q", tt.expectedMessage, errObj.Message)
+		}
+	}
+}
+func TestStringInterpolation(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`let name = "Mata"; "Hello ${name}!"`, "Hello Mata!"},
+		{`let items = [1, 2, 3]; "you have ${len(items)} items"`, "you have 3 items"},
+		{`"${1 + 1}${true}${[1, "a"]}"`, "2true[1, a]"},
+		{`"sum: ${ {"a": 1}["a"] + 2.5 }"`, "sum: 3.5"},
+		{`"outer ${"inner ${1 * 2}"}"`, "outer inner 2"},
+		{`"price: \${x}"`, "price: ${x}"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		str, ok := evaluated.(*object.String)
+		if !ok {
+			t.Errorf("object is not String. got=%T (%+v)", evaluated, evaluated)
+			continue
+		}
+		if str.Value != tt.expected {
+			t.Errorf("wrong value. expected=%q,got=%q", tt.expected, str.Value)
+		}
+	}
+
+	evaluated := testEval(`"a ${missing} b"`)
+	if errObj, ok := evaluated.(*object.ErrorType); !ok || errObj.Message != "identifier not found: missing" {
+		t.Errorf("expected identifier not found error,got=%s", evaluated.Inspect())
+	}
+}
+func TestLoops(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let i = 0; let sum = 0; while (i < 5) { i += 1; sum += i }; sum", 15},
+		{"let i = 0; while (i < 100000) { i += 1 }; i", 100000},
+		{"let i = 0; while (true) { i += 1; if (i == 3) { break } }; i", 3},
+		{"let i = 0; let sum = 0; while (i < 5) { i += 1; if (i % 2 == 0) { continue }; sum += i }; sum", 9},
+		{"let sum = 0; for (x in [1, 2, 3]) { sum += x }; sum", 6},
+		{"let sum = 0; for (i, x in [10, 20, 30]) { sum += i * x }; sum", 80},
+		{"let s = \"\"; for (k in {\"b\": 2, \"a\": 1, \"c\": 3}) { s += k }; s", "abc"},
+		{"let sum = 0; for (k, v in {\"a\": 1, \"b\": 2}) { sum += v }; sum", 3},
+		{"let s = \"\"; for (c in \"héllo\") { s = c + s }; s", "olléh"},
+		{"let sum = 0; for (i in 5) { sum += i }; sum", 10},
+		{"let n = 0; for (i in 0) { n += 1 }; n", 0},
+		{"let sum = 0; for (x in [1, 2, 3, 4]) { if (x == 3) { break }; sum += x }; sum", 3},
+		{"let sum = 0; for (x in [1, 2, 3, 4]) { if (x == 3) { continue }; sum += x }; sum", 7},
+		{"let n = 0; for (i in 3) { for (j in 3) { if (j == 1) { break }; n += 1 } }; n", 3},
+		{"let f = fn() { for (x in [1, 2, 3]) { if (x == 2) { return x * 10 } }; 0 }; f()", 20},
+		{"let f = fn() { while (true) { return 7 } }; f()", 7},
+		{"while (false) { 1 }", nil},
+		{"for (x in []) { x }", nil},
+		{"let x = 1; for (x in [5]) { x }; x", 1},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntergerObject(t, evaluated, int64(expected))
+		case string:
+			str, ok := evaluated.(*object.String)
+			if !ok || str.Value != expected {
+				t.Errorf("input %q: expected=%q,got=%s", tt.input, expected, evaluated.Inspect())
+			}
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+func TestLoopErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"for (x in true) { x }", "BOOLEAN is not iterable"},
+		{"for (i, x in 3) { x }", "cannot use two loop variables with INTEGER"},
+		{"for (x in -1) { x }", "negative loop count: -1"},
+		{"while (y) { 1 }", "identifier not found: y"},
+		{"for (x in [1]) { x + true }", "type mismatch: INTEGER + BOOLEAN"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		err, ok := evaluated.(*object.ErrorType)
+		if !ok || err.Message != tt.expected {
+			t.Errorf("input %q: expected error %q,got=%s", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+func TestControlFlowInExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let out = []; for (x in [1, 2, 3]) { let v = if (x == 2) { break } else { x }; out.push(v) }; out.len()", 1},
+		{"let sum = 0; for (n in [1, 2, 3]) { let w = match (n) { 2 => { continue }, _ => n }; sum += w }; sum", 4},
+		{"let sum = 0; for (x in [1, 2, 3]) { sum += if (x == 2) { continue } else { x } }; sum", 4},
+		{"let sum = 0; for (x in [1, 2, 3]) { sum = sum + if (x == 3) { break } else { x } }; sum", 3},
+		{"let sum = 0; for (x in [1, 2, 3]) { sum += -if (x == 2) { continue } else { x } }; sum", -4},
+		{"let n = 0; for (x in [1, 2, 3]) { len(if (x == 2) { break } else { [x] }); n += 1 }; n", 1},
+		{"let a = []; for (x in [1, 2, 3]) { a.push([x, if (x == 2) { continue } else { x }]) }; a.len()", 2},
+		{"let n = 0; for (x in [1, 2, 3]) { if (if (x == 2) { break } else { true }) { n += 1 } }; n", 1},
+		{"let n = 0; for (x in [1, 2]) { while (if (x == 1) { continue } else { false }) { 1 }; n += x }; n", 2},
+		{"let f = fn(x) { let v = if (x > 0) { return 1 } else { 2 }; v * 10 }; f(5)", 1},
+		{"let f = fn(x) { let v = if (x > 0) { return 1 } else { 2 }; v * 10 }; f(-5)", 20},
+		{"let f = fn(x) { [match (x) { 0 => { return \"zero\" }, _ => x }] }; f(0)", "zero"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntergerObject(t, evaluated, int64(expected))
+		case string:
+			str, ok := evaluated.(*object.String)
+			if !ok || str.Value != expected {
+				t.Errorf("input %q: expected=%q,got=%s", tt.input, expected, evaluated.Inspect())
+			}
+		}
+	}
+}
+func TestControlFlowInDefaultValue(t *testing.T) {
+	input := "let n = 0; for (x in [1]) { let f = fn(a = if (x == 1) { break } else { 1 }) { a }; n = f() }; n"
+	evaluated := testEval(input)
+	err, ok := evaluated.(*object.ErrorType)
+	if !ok || err.Message != "cannot use BREAK in default value of a" {
+		t.Errorf("expected error,got=%s", evaluated.Inspect())
+	}
+}
+func TestPropertyAndMethods(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`let user = {"name": "bob", "age": 30}; user.age`, 30},
+		{`let user = {"name": "bob"}; user.email`, nil},
+		{`{"a": {"b": 5}}.a.b`, 5},
+		{`{"len": 7}.len`, 7},
+		{`{"a": 1, "b": 2}.len()`, 2},
+		{`{"b": 1, "a": 2}.keys().join(",")`, "a,b"},
+		{`{"b": 1, "a": 2}.values()[0]`, 2},
+		{`{"a": 1}.has("a")`, true},
+		{`let h = {"a": 1, "b": 2}; h.delete("a"); h.len()`, 1},
+		{`let h = {"f": fn(x) { x * 2 }}; h.f(21)`, 42},
+		{`"héllo".len()`, 5},
+		{`"abc".upper()`, "ABC"},
+		{`"ABC".lower()`, "abc"},
+		{`"  x ".trim()`, "x"},
+		{`"a,b,c".split(",")[2]`, "c"},
+		{`"hello".contains("ell")`, true},
+		{`"a-b-c".replace("-", "+")`, "a+b+c"},
+		{`let up = "abc".upper; up()`, "ABC"},
+		{`[1, 2, 3].len()`, 3},
+		{`[1, 2, 3].first()`, 1},
+		{`[1, 2, 3].last()`, 3},
+		{`let arr = [1]; arr.push(2).push(3); arr.len()`, 3},
+		{`let arr = [1, 2]; arr.pop() + arr.len()`, 3},
+		{`[].pop()`, nil},
+		{`[1, 2, 3].join("-")`, "1-2-3"},
+		{`[1, "a", true].contains("a")`, true},
+		{`[1, 2].contains(2.0)`, true},
+		{`[1, 2].contains(3)`, false},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntergerObject(t, evaluated, int64(expected))
+		case bool:
+			testBoolean(t, evaluated, expected)
+		case string:
+			str, ok := evaluated.(*object.String)
+			if !ok || str.Value != expected {
+				t.Errorf("input %q: expected=%q,got=%s", tt.input, expected, evaluated.Inspect())
+			}
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+func TestMethodErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`5.abs()`, "INTEGER has no method abs"},
+		{`"abc".upper(1)`, "wrong number of arguments to `upper`. got=1, want=0"},
+		{`"abc".split(1)`, "argument 1 to `split` must be STRING, got INTEGER"},
+		{`{"a": 1}.b()`, "not a function: NULL"},
+		{`x.y`, "identifier not found: x"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		err, ok := evaluated.(*object.ErrorType)
+		if !ok || err.Message != tt.expected {
+			t.Errorf("input %q: expected error %q,got=%s", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+func TestRegisterMethod(t *testing.T) {
+	object.RegisterMethod(object.INTEGER_OBJ, "double", func(receiver object.Object, args ...object.Object) object.Object {
+		return &object.Interger{Value: receiver.(*object.Interger).Value * 2}
+	})
+	testIntergerObject(t, testEval("let x = 21; x.double()"), 42)
+}
+func TestSliceExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"[1, 2, 3, 4, 5][1:3]", []int64{2, 3}},
+		{"[1, 2, 3, 4, 5][:-1]", []int64{1, 2, 3, 4}},
+		{"[1, 2, 3, 4, 5][-2:]", []int64{4, 5}},
+		{"[1, 2, 3, 4, 5][::2]", []int64{1, 3, 5}},
+		{"[1, 2, 3, 4, 5][::-1]", []int64{5, 4, 3, 2, 1}},
+		{"[1, 2, 3, 4, 5][3:0:-1]", []int64{4, 3, 2}},
+		{"[1, 2, 3, 4, 5][-1:-4:-2]", []int64{5, 3}},
+		{"[1, 2, 3][:]", []int64{1, 2, 3}},
+		{"[1, 2, 3][-10:10]", []int64{1, 2, 3}},
+		{"[1, 2, 3][2:1]", []int64{}},
+		{"[1, 2, 3][5:]", []int64{}},
+		{"[][::-1]", []int64{}},
+		{"let a = [1, 2, 3]; let b = a[:]; b[0] = 9; a", []int64{1, 2, 3}},
+		{`"hello"[1:3]`, "el"},
+		{`"hello"[::-1]`, "olleh"},
+		{`"héllo wörld"[-5:]`, "wörld"},
+		{`"héllo"[1]`, "é"},
+		{`"héllo"[-1]`, "o"},
+		{`"abc"[3]`, nil},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case []int64:
+			arr, ok := evaluated.(*object.Array)
+			if !ok || len(arr.Elements) != len(expected) {
+				t.Errorf("input %q: expected array %v,got=%s", tt.input, expected, evaluated.Inspect())
+				continue
+			}
+			for i, el := range arr.Elements {
+				testIntergerObject(t, el, expected[i])
+			}
+		case string:
+			str, ok := evaluated.(*object.String)
+			if !ok || str.Value != expected {
+				t.Errorf("input %q: expected=%q,got=%s", tt.input, expected, evaluated.Inspect())
+			}
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+func TestSliceErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"[1, 2][::0]", "slice step cannot be zero"},
+		{`[1, 2]["a":]`, "slice index must be INTEGER, got STRING"},
+		{`{"a": 1}[1:]`, "slice operator not supported: HASH"},
+		{`"abc"["a"]`, "index:a is not INTEGER"},
+		{"5[0]", "index operator not supported: INTEGER"},
+		{"[1][x:]", "identifier not found: x"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		err, ok := evaluated.(*object.ErrorType)
+		if !ok || err.Message != tt.expected {
+			t.Errorf("input %q: expected error %q,got=%s", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+func TestDestructuringLet(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let [a, b] = [1, 2]; a * 10 + b", 12},
+		{"let [a, ...rest] = [1, 2, 3]; rest", []int64{2, 3}},
+		{"let [a, b, ...rest] = [1, 2]; rest", []int64{}},
+		{"let [...all] = [1, 2]; all", []int64{1, 2}},
+		{"let xs = [1, 2, 3]; let [...copy] = xs; copy[0] = 9; xs", []int64{1, 2, 3}},
+		{"let [a, [b, c]] = [1, [2, 3]]; a + b + c", 6},
+		{"let divmod = fn(a, b) { [a / b, a % b] }; let [q, r] = divmod(17, 5); q * 10 + r", 32},
+		{`let {name, age: years} = {"name": "bob", "age": 30}; years`, 30},
+		{`let {name, age: years} = {"name": "bob", "age": 30}; name`, "bob"},
+		{`let {"first-name": first} = {"first-name": "ann"}; first`, "ann"},
+		{`let {a, ...others} = {"a": 1, "b": 2, "c": 3}; others.len()`, 2},
+		{`let {pos: [x, y]} = {"pos": [3, 4]}; x * y`, 12},
+		{`let [{id}] = [{"id": 7}]; id`, 7},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntergerObject(t, evaluated, int64(expected))
+		case string:
+			str, ok := evaluated.(*object.String)
+			if !ok || str.Value != expected {
+				t.Errorf("input %q: expected=%q,got=%s", tt.input, expected, evaluated.Inspect())
+			}
+		case []int64:
+			arr, ok := evaluated.(*object.Array)
+			if !ok || len(arr.Elements) != len(expected) {
+				t.Errorf("input %q: expected array %v,got=%s", tt.input, expected, evaluated.Inspect())
+				continue
+			}
+			for i, el := range arr.Elements {
+				testIntergerObject(t, el, expected[i])
+			}
+		}
+	}
+}
+func TestDestructuringErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let [a, b] = [1, 2, 3]", "1:5: array pattern expects 2 elements, got 3"},
+		{"let [a, b, c, ...d] = [1]", "1:5: array pattern expects at least 3 elements, got 1"},
+		{"let [a] = 5", "1:5: cannot destructure INTEGER as array"},
+		{`let {a} = [1]`, "1:5: cannot destructure ARRAY as hash"},
+		{`let {a, b: bee} = {"a": 1}`, "1:12: key not found: b"},
+		{`let [x, [y]] = [1, [2, 3]]`, "1:9: array pattern expects 1 elements, got 2"},
+		{`let [a] = [b]`, "1:12: identifier not found: b"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		err, ok := evaluated.(*object.ErrorType)
+		if !ok || err.Inspect() != "ERROR: "+tt.expected {
+			t.Errorf("input %q: expected error %q,got=%s", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+func TestDefaultsVariadicsAndSpread(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let f = fn(a, b = 10) { a + b }; f(1)", 11},
+		{"let f = fn(a, b = 10) { a + b }; f(1, 2)", 3},
+		{"let f = fn(a, b = a * 2) { b }; f(4)", 8},
+		{"let n = 0; let next = fn() { n += 1 }; let f = fn(a = next()) { a }; f(); f(5); f(); n", 2},
+		{"let f = fn(...args) { args }; f()", []int64{}},
+		{"let f = fn(a, ...rest) { rest }; f(1, 2, 3)", []int64{2, 3}},
+		{"let f = fn(a, b = 2, ...rest) { [a, b, len(rest)] }; f(1)", []int64{1, 2, 0}},
+		{"let add = fn(a, b, c) { a + b + c }; let xs = [1, 2, 3]; add(...xs)", 6},
+		{"let add = fn(a, b, c) { a + b + c }; add(1, ...[2, 3])", 6},
+		{"let sum = fn(...xs) { let s = 0; for (x in xs) { s += x }; s }; sum(...[1, 2], 3, ...[4])", 10},
+		{"len(...[[1, 2, 3]])", 3},
+		{"let a = [1, 2]; let b = [3]; [...a, ...b, 4]", []int64{1, 2, 3, 4}},
+		{"let a = [1, 2]; let b = [...a]; b[0] = 9; a", []int64{1, 2}},
+		{`let defaults = {"a": 1, "b": 2}; let h = {...defaults, "b": 3}; [h.a, h.b]`, []int64{1, 3}},
+		{`let h = {"a": 0, ...{"a": 1}}; h.a`, 1},
+		{`let h = {...{"a": 1}, ...{"a": 2, "b": 3}}; [h.a, h.b]`, []int64{2, 3}},
+		{`{"a": 1, "a": 2}.a`, 2},
+		{"let fact = fn(n) { if (n < 2) { 1 } else { n * fact(n - 1) } }; fact(10)", 3628800},
+		{"let counter = fn() { let c = 0; fn() { c += 1 } }; let a = counter(); let b = counter(); a(); a(); b(); a()", 3},
+		{"let x = 1; let f = fn() { x }; let g = fn() { let x = 2; f() }; g()", 1},
+		{"let fs = []; for (i in 3) { fs = push(fs, fn() { i }) }; fs[1]()", 1},
+		{"let f = fn() {}; f()", nil},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntergerObject(t, evaluated, int64(expected))
+		case []int64:
+			arr, ok := evaluated.(*object.Array)
+			if !ok || len(arr.Elements) != len(expected) {
+				t.Errorf("input %q: expected array %v,got=%s", tt.input, expected, evaluated.Inspect())
+				continue
+			}
+			for i, el := range arr.Elements {
+				testIntergerObject(t, el, expected[i])
+			}
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+func TestCallErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let f = fn(a, b) { a }; f(1)", "wrong number of arguments. got=1, want=2"},
+		{"let f = fn(a, b = 1) { a }; f(1, 2, 3)", "wrong number of arguments. got=3, want=1 to 2"},
+		{"let f = fn(a, ...b) { a }; f()", "wrong number of arguments. got=0, want=at least 1"},
+		{"let f = fn(a = x) { a }; f()", "identifier not found: x"},
+		{"let f = fn(a) { a }; f(...5)", "cannot spread INTEGER, expected ARRAY"},
+		{"[...{}]", "cannot spread HASH, expected ARRAY"},
+		{"{...[1]}", "cannot spread ARRAY, expected HASH"},
+		{"let f = fn(a) { a }; f(y)", "identifier not found: y"},
+		{"len(z)", "identifier not found: z"},
+		{"5(1)", "not a function: INTEGER"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		err, ok := evaluated.(*object.ErrorType)
+		if !ok || err.Message != tt.expected {
+			t.Errorf("input %q: expected error %q,got=%s", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+func TestKeywordArguments(t *testing.T) {
+	connect := `let connect = fn(host, port = 80, secure = false) { [host, port, secure] };`
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{connect + `connect(host: "x", port: 8080)`, []string{"x", "8080", "false"}},
+		{connect + `connect("x", secure: true)`, []string{"x", "80", "true"}},
+		{connect + `connect(port: 1, host: "y")`, []string{"y", "1", "false"}},
+		{`let f = fn(a, b = a * 2) { [a, b] }; f(a: 3)`, []string{"3", "6"}},
+		{`let f = fn(a, ...rest) { [a, len(rest)] }; f(a: 1)`, []string{"1", "0"}},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		arr, ok := evaluated.(*object.Array)
+		if !ok || len(arr.Elements) != len(tt.expected) {
+			t.Errorf("input %q: expected %v,got=%s", tt.input, tt.expected, evaluated.Inspect())
+			continue
+		}
+		for i, el := range arr.Elements {
+			if el.Inspect() != tt.expected[i] {
+				t.Errorf("input %q: element %d expected=%s,got=%s", tt.input, i, tt.expected[i], el.Inspect())
+			}
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{connect + `connect("x", host: "y")`, "multiple values for argument: host"},
+		{connect + `connect(hots: "x")`, "unknown keyword argument: hots"},
+		{connect + `connect(port: 1)`, "missing argument: host"},
+		{connect + `connect(host: z)`, "identifier not found: z"},
+		{`len("abc", x: 1)`, "builtin function does not accept keyword arguments"},
+		{`puts(1, end: "")`, "unknown keyword argument to `puts`: end"},
+		{`puts(1, sep: 2)`, "argument `sep` to `puts` must be STRING, got INTEGER"},
+	}
+	for _, tt := range errorTests {
+		evaluated := testEval(tt.input)
+		err, ok := evaluated.(*object.ErrorType)
+		if !ok || err.Message != tt.expected {
+			t.Errorf("input %q: expected error %q,got=%s", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+func TestPuts(t *testing.T) {
+	var out bytes.Buffer
+	output = &out
+	defer func() { output = os.Stdout }()
+
+	testEval(`puts("a", 1); puts("x", "y", sep: ", "); puts()`)
+	if out.String() != "a\n1\nx, y\n" {
+		t.Errorf("wrong output. got=%q", out.String())
+	}
+}
+func TestArrowFunctions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let double = x => x * 2; double(4)", 8},
+		{"let add = (a, b) => a + b; add(1, 2)", 3},
+		{"let f = () => 5; f()", 5},
+		{"let f = (a, b = 10) => a + b; f(1)", 11},
+		{"let f = (a, ...rest) => rest; f(1, 2, 3)", []int64{2, 3}},
+		{"let f = x => { if (x > 0) { return 1 }; -1 }; [f(5), f(-5)]", []int64{1, -1}},
+		{"let adder = x => y => x + y; adder(1)(2)", 3},
+		{"let apply = fn(f, xs) { let r = []; for (x in xs) { r.push(f(x)) }; r }; apply(x => x * x, [1, 2, 3])", []int64{1, 4, 9}},
+		{"(x => x + 1)(1)", 2},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntergerObject(t, evaluated, int64(expected))
+		case []int64:
+			arr, ok := evaluated.(*object.Array)
+			if !ok || len(arr.Elements) != len(expected) {
+				t.Errorf("input %q: expected array %v,got=%s", tt.input, expected, evaluated.Inspect())
+				continue
+			}
+			for i, el := range arr.Elements {
+				testIntergerObject(t, el, expected[i])
+			}
+		}
+	}
+}
+func TestMatchExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`match (2) { 1 => "one", 2 => "two", _ => "many" }`, "two"},
+		{`match (5) { 1 => "one", 2 => "two", _ => "many" }`, "many"},
+		{`match ("b") { "a" => 1, "b" => 2 }`, 2},
+		{`match (-1) { -1 => "neg", _ => "other" }`, "neg"},
+		{`match (2.0) { 2 => "two" }`, "two"},
+		{`match (true) { false => "f", true => "t" }`, "t"},
+		{`match ("1") { 1 => "int", _ => "other" }`, "other"},
+		{`match ([1, 2]) { [a] => a, [a, b] => a + b }`, 3},
+		{`match ([1, 2, 3]) { [a, b] => 0, [a, ...] => a }`, 1},
+		{`match ([1, 2, 3]) { [1, ...rest] => rest }`, []int64{2, 3}},
+		{`match ([1, [2, 3]]) { [x, [2, y]] => x + y }`, 4},
+		{`match ([0, 1]) { [1, x] => x, [0, x] => x * 10 }`, 10},
+		{`match ({"type": "point", "x": 1, "y": 2}) { {"type": "circle", r} => r, {"type": "point", x, y} => x + y }`, 3},
+		{`match ({"a": 1, "b": 2}) { {a, ...rest} => rest.b }`, 2},
+		{`match ({"a": 1}) { {b} => "b", {a: 2} => "a2", {a} => a }`, 1},
+		{`match (1) { [a] => a, {a} => a, a => a + 1 }`, 2},
+		{`match (7) { n if n % 2 == 0 => "even", n => "odd" }`, "odd"},
+		{`match ([3, 4]) { [a, b] if a > b => a, [a, b] => b }`, 4},
+		{`let x = 10; match (1) { x => x }; x`, 10},
+		{`let f = fn(n) { match (n) { 0 => { return "zero" }, _ => "nonzero" } }; f(0)`, "zero"},
+		{`let f = fn(n) { return match (n) { 0 => 1, _ => n * f(n - 1) } }; f(5)`, 120},
+		{`match (1) { 2 => "two" }`, nil},
+		{`match (1) { _ => {} }`, nil},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntergerObject(t, evaluated, int64(expected))
+		case string:
+			str, ok := evaluated.(*object.String)
+			if !ok || str.Value != expected {
+				t.Errorf("input %q: expected %q,got=%s", tt.input, expected, evaluated.Inspect())
+			}
+		case []int64:
+			arr, ok := evaluated.(*object.Array)
+			if !ok || len(arr.Elements) != len(expected) {
+				t.Errorf("input %q: expected array %v,got=%s", tt.input, expected, evaluated.Inspect())
+				continue
+			}
+			for i, el := range arr.Elements {
+				testIntergerObject(t, el, expected[i])
+			}
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+
+	evaluated := testEval(`match (1) { x if y => x }`)
+	err, ok := evaluated.(*object.ErrorType)
+	if !ok || err.Message != "identifier not found: y" {
+		t.Errorf("expected guard error,got=%s", evaluated.Inspect())
+	}
+}
+func TestNullAndOptionalChaining(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"null", nil},
+		{"null == null", true},
+		{"null != null", false},
+		{"1 == null", false},
+		{"null != 1", true},
+		{`{"a": 1}.b == null`, true},
+		{"[1][5] == null", true},
+		{"null ?? 1", 1},
+		{"2 ?? 1", 2},
+		{"false ?? 1", false},
+		{"null ?? null ?? 3", 3},
+		{"let n = 0; let f = fn() { n += 1 }; 1 ?? f(); n", 0},
+		{`let h = {"a": {"b": 2}}; h?.a?.b`, 2},
+		{`let h = {"a": {"b": 2}}; h.x?.b`, nil},
+		{`let h = null; h?.a.b.c`, nil},
+		{`let h = null; h?.a.b() ?? 5`, 5},
+		{`let h = {}; h.a?.len()`, nil},
+		{`let h = {"a": [1, 2]}; h.a?.len()`, 2},
+		{`let xs = null; xs?[0]`, nil},
+		{`let xs = [1, 2, 3]; xs?[-1]`, 3},
+		{`let xs = null; xs?[1:]`, nil},
+		{`let xs = [1, [2, 3]]; xs?[1]?[0]`, 2},
+		{`let f = null; f?.(1)`, nil},
+		{`let f = fn(x) { x * 2 }; f?.(2)`, 4},
+		{`let n = 0; let f = fn() { n += 1 }; let g = null; g?.(f()); n`, 0},
+		{`let users = [{"name": "a", "address": {"city": "x"}}, {"name": "b"}]; let cities = []; for (u in users) { cities.push(u.address?.city ?? "unknown") }; cities.join(",")`, "x,unknown"},
+		{"match (null) { null => 1, _ => 2 }", 1},
+		{"match (0) { null => 1, _ => 2 }", 2},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntergerObject(t, evaluated, int64(expected))
+		case bool:
+			testBoolean(t, evaluated, expected)
+		case string:
+			str, ok := evaluated.(*object.String)
+			if !ok || str.Value != expected {
+				t.Errorf("input %q: expected %q,got=%s", tt.input, expected, evaluated.Inspect())
+			}
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+func TestNullErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"null + 1", "type mismatch: NULL + INTEGER"},
+		{"null < null", "unknown operator: NULL < NULL"},
+		{"let h = null; h.a", "cannot read property a of NULL"},
+		{"let h = null; h?.a.b; h.c", "cannot read property c of NULL"},
+		{"null.len()", "cannot read property len of NULL"},
+		{`let user = {"address": null}; user.address.city`, "cannot read property city of NULL"},
+		{"let f = null; f()", "not a function: NULL"},
+		{`let h = {}; h?.a = 1`, "cannot assign to optional chain: (h?.a)"},
+		{`let xs = [1]; xs?[0] += 1`, "cannot assign to optional chain: (xs?[0])"},
+		{"null ?? x", "identifier not found: x"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		err, ok := evaluated.(*object.ErrorType)
+		if !ok {
+			t.Errorf("input %q: no error object returned. got=%T(%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if err.Message != tt.expected {
+			t.Errorf("input %q: wrong error message. expected=%q, got=%q", tt.input, tt.expected, err.Message)
+		}
+	}
+}
+func TestConditionalAndComparisonChain(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"true ? 1 : 2", 1},
+		{"false ? 1 : 2", 2},
+		{"null ? 1 : 2", 2},
+		{"0 ? 1 : 2", 1},
+		{"let x = 5; x > 3 ? x * 2 : 0", 10},
+		{"let sign = fn(n) { n > 0 ? 1 : n < 0 ? -1 : 0 }; [sign(5), sign(-5), sign(0)]", []int64{1, -1, 0}},
+		{"let n = 0; let f = fn() { n += 1 }; true ? 1 : f(); false ? f() : 2; n", 0},
+		{"let x = null; let y = x ?? 3 > 2 ? 10 : 20; y", 10},
+		{"0 <= 5 < 10", true},
+		{"0 <= 10 < 10", false},
+		{"0 <= -1 < 10", false},
+		{"1 < 2 < 3 < 4", true},
+		{"1 < 3 > 2", true},
+		{"1 < 2.5 <= 3", true},
+		{"let n = 0; let f = fn() { n += 1; n }; 0 < f() < 2; n", 1},
+		{"let n = 0; let f = fn() { n += 1; 5 }; 10 < f() < 20; n", 1},
+		{"let n = 0; let f = fn() { n += 1; 5 }; 10 < 0 < f(); n", 0},
+		{"let x = 7; 0 <= x < 10 ? x : -1", 7},
+		{"let x = 12; 0 <= x < 10 ? x : -1", -1},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntergerObject(t, evaluated, int64(expected))
+		case bool:
+			testBoolean(t, evaluated, expected)
+		case []int64:
+			arr, ok := evaluated.(*object.Array)
+			if !ok || len(arr.Elements) != len(expected) {
+				t.Errorf("input %q: expected array %v,got=%s", tt.input, expected, evaluated.Inspect())
+				continue
+			}
+			for i, el := range arr.Elements {
+				testIntergerObject(t, el, expected[i])
+			}
+		}
+	}
+
+	evaluated := testEval(`1 < "a" < 3`)
+	err, ok := evaluated.(*object.ErrorType)
+	if !ok || err.Message != "type mismatch: INTEGER < STRING" {
+		t.Errorf("expected type mismatch error,got=%s", evaluated.Inspect())
+	}
+}
+func TestConstAndFreeze(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"const x = 5; x * 2", 10},
+		{"const [a, b] = [1, 2]; a + b", 3},
+		{`const {a, ...rest} = {"a": 1, "b": 2}; rest.b`, 2},
+		{"let x = 1; const x = 2; x", 2},
+		{"const x = 1; let f = fn() { let x = 2; x = 3; x }; f() + x", 4},
+		{"const x = 1; let f = fn(x) { x += 1; x }; f(5)", 6},
+		{"let total = 0; for (i in 3) { const sq = i * i; total += sq }; total", 5},
+		{"const xs = [1]; xs.push(2); xs.len()", 2},
+		{"let xs = freeze([1, 2]); xs[0]", 1},
+		{"let xs = freeze([1, 2]); let ys = [...xs, 3]; ys.push(4); ys.len()", 4},
+		{"let xs = freeze([1, 2]); let ys = xs[:]; ys[0] = 9; ys[0] + xs[0]", 10},
+		{"let xs = freeze([1, 2]); push(xs, 3).len()", 3},
+		{`let h = freeze({"a": 1}); h.b ?? h.a`, 1},
+		{"freeze(5)", 5},
+		{"let xs = [1]; xs.push(xs); freeze(xs); xs.len()", 2},
+	}
+	for _, tt := range tests {
+		testIntergerObject(t, testEval(tt.input), int64(tt.expected.(int)))
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{"let f = fn() { x = 2 }; const x = 1; f()", "cannot assign to constant x"},
+		{"let f = fn() { x += 2 }; const x = 1; f()", "cannot assign to constant x"},
+		{"let xs = freeze([1, 2]); xs[0] = 3", "cannot modify frozen ARRAY"},
+		{"let xs = freeze([1, 2]); xs[-1] += 3", "cannot modify frozen ARRAY"},
+		{"let xs = freeze([1, 2]); xs.push(3)", "cannot modify frozen ARRAY"},
+		{"let xs = freeze([1, 2]); xs.pop()", "cannot modify frozen ARRAY"},
+		{`let h = freeze({"a": 1}); h["b"] = 2`, "cannot modify frozen HASH"},
+		{`let h = freeze({"a": 1}); h.a = 2`, "cannot modify frozen HASH"},
+		{`let h = freeze({"a": 1}); h.delete("a")`, "cannot modify frozen HASH"},
+		{`let h = freeze({"a": [1], "b": {"c": 1}}); h.a.push(2)`, "cannot modify frozen ARRAY"},
+		{`let h = freeze({"a": [1], "b": {"c": 1}}); h.b.c = 2`, "cannot modify frozen HASH"},
+		{"freeze()", "wrong number of arguments. got=0, want=1"},
+	}
+	for _, tt := range errorTests {
+		evaluated := testEval(tt.input)
+		err, ok := evaluated.(*object.ErrorType)
+		if !ok {
+			t.Errorf("input %q: no error object returned. got=%T(%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if err.Message != tt.expected {
+			t.Errorf("input %q: wrong error message. expected=%q, got=%q", tt.input, tt.expected, err.Message)
+		}
+	}
+
+	//在同一个环境中分别解析和执行，解析时看不到之前定义的常量，由求值时检查
+	env := object.NewEnvironment(nil)
+	Eval(parser.New(lexer.New("const x = 1")).ParseProgram(), env)
+	for _, input := range []string{"x = 2", "let x = 2", "const x = 3"} {
+		evaluated := Eval(parser.New(lexer.New(input)).ParseProgram(), env)
+		err, ok := evaluated.(*object.ErrorType)
+		if !ok || !strings.HasSuffix(err.Message, "constant x") {
+			t.Errorf("input %q: expected constant error,got=%s", input, evaluated.Inspect())
+		}
+	}
+	if value, _ := env.Get("x"); value.Inspect() != "1" {
+		t.Errorf("constant x was modified. got=%s", value.Inspect())
+	}
+}
+func TestIncrementExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let i = 1; i++; i", 2},
+		{"let i = 1; i--; i", 0},
+		{"let i = 1; i++", 1},
+		{"let i = 1; ++i", 2},
+		{"let i = 1; i--", 1},
+		{"let i = 1; --i", 0},
+		{"let f = 1.5; f++; f", 2.5},
+		{"let arr = [1, 2]; arr[1]++; arr", []int64{1, 3}},
+		{"let arr = [1, 2]; --arr[-1]; arr", []int64{1, 1}},
+		{`let h = {"n": 1}; h.n++; h.n`, 2},
+		{`let h = {"n": 1}; ++h["n"]`, 2},
+		{"let arr = [0, 0]; let i = 0; let next = fn() { i += 1; i - 1 }; arr[next()]++; [arr[0], arr[1], i];", []int64{1, 0, 1}},
+		{"let n = 0; for (x in 5) { n++ }; n", 5},
+		{"let i = 5; -i++", -5},
+		{"let x = 5; let y = 2; x--y", 7},
+		{"let x = 5; x--1", 6},
+		{"let x = 5; let y = 2; x-- - y; x", 4},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntergerObject(t, evaluated, int64(expected))
+		case float64:
+			testFloatObject(t, evaluated, expected)
+		case []int64:
+			arr, ok := evaluated.(*object.Array)
+			if !ok || len(arr.Elements) != len(expected) {
+				t.Errorf("input %q: expected array %v,got=%s", tt.input, expected, evaluated.Inspect())
+				continue
+			}
+			for i, el := range arr.Elements {
+				testIntergerObject(t, el, expected[i])
+			}
+		}
+	}
+}
+func TestIncrementErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"i++", "identifier not found: i"},
+		{`let s = "a"; s++`, "unknown operator: STRING++"},
+		{`let s = "a"; --s`, "unknown operator: --STRING"},
+		{`let h = {}; h.n++`, "key not found: n"},
+		{"let h = null; h?.n++", "cannot assign to optional chain: (h?.n)"},
+		{"let a = freeze([1]); a[0]++", "cannot modify frozen ARRAY"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		err, ok := evaluated.(*object.ErrorType)
+		if !ok || err.Message != tt.expected {
+			t.Errorf("input %q: expected error %q,got=%s", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package evaluator
+
+import (
+	"fmt"
+	"interpreter/ast"
+	"interpreter/object"
+)
+
+/*
+对 left[index] 求值
+数组和字符串支持负数下标，-1表示最后一个元素，越界时返回null；字符串按字符而不是字节索引
+哈希表中不存在的key同样返回null
+*/
+func evalIndexExpression(left, index object.Object) object.Object {
+	switch left := left.(type) {
+	case *object.Array:
+		idx, ok := index.(*object.Interger)
+		if !ok {
+			return &object.ErrorType{Message: fmt.Sprintf("index:%s is not INTEGER", index.Inspect())}
+		}
+		i, ok := normalizeIndex(idx.Value, len(left.Elements))
+		if !ok {
+			return NULL
+		}
+		return left.Elements[i]
+	case *object.String:
+		idx, ok := index.(*object.Interger)
+		if !ok {
+			return &object.ErrorType{Message: fmt.Sprintf("index:%s is not INTEGER", index.Inspect())}
+		}
+		runes := []rune(left.Value)
+		i, ok := normalizeIndex(idx.Value, len(runes))
+		if !ok {
+			return NULL
+		}
+		return &object.String{Value: string(runes[i])}
+	case *object.Hash:
+		hashable, ok := index.(object.Hashable)
+		if !ok {
+			return unusableHashKeyError(index)
+		}
+		value, ok := left.Pairs[hashable.HashKey()]
+		if !ok {
+			return NULL
+		}
+		return value.Value
+	}
+	return &object.ErrorType{Message: fmt.Sprintf("index operator not supported: %s", left.Type())}
+}
+
+// 将负数下标转换为从头开始的下标，ok表示下标在 [0, length) 之内
+func normalizeIndex(index int64, length int) (int64, bool) {
+	if index < 0 {
+		index += int64(length)
+	}
+	return index, index >= 0 && index < int64(length)
+}
+
+/*
+对切片 left[start:stop:step] 求值，规则与python相同
+省略的部分取默认值，负数从末尾开始计算，越界的start和stop被截断到边界，step为负数时反向切片
+结果是新的数组或字符串，修改切片不会影响原数组
+*/
+func evalSliceExpression(node *ast.SliceExpression, left object.Object, env *object.Environment) object.Object {
+	var bounds [3]*int64
+	for i, exp := range []ast.Expression{node.Start, node.Stop, node.Step} {
+		if exp == nil {
+			continue
+		}
+		value := Eval(exp, env)
+		if isAbrupt(value) {
+			return value
+		}
+		integer, ok := value.(*object.Interger)
+		if !ok {
+			return &object.ErrorType{Message: fmt.Sprintf("slice index must be INTEGER, got %s", value.Type())}
+		}
+		bounds[i] = &integer.Value
+	}
+
+	switch left := left.(type) {
+	case *object.Array:
+		indexes, err := sliceIndexes(len(left.Elements), bounds[0], bounds[1], bounds[2])
+		if err != nil {
+			return err
+		}
+		elements := make([]object.Object, len(indexes))
+		for i, idx := range indexes {
+			elements[i] = left.Elements[idx]
+		}
+		return &object.Array{Elements: elements}
+	case *object.String:
+		runes := []rune(left.Value)
+		indexes, err := sliceIndexes(len(runes), bounds[0], bounds[1], bounds[2])
+		if err != nil {
+			return err
+		}
+		result := make([]rune, len(indexes))
+		for i, idx := range indexes {
+			result[i] = runes[idx]
+		}
+		return &object.String{Value: string(result)}
+	}
+	return &object.ErrorType{Message: fmt.Sprintf("slice operator not supported: %s", left.Type())}
+}
+
+// 计算切片依次选中的下标，start、stop、step为nil时表示省略
+func sliceIndexes(length int, start, stop, step *int64) ([]int64, *object.ErrorType) {
+	n := int64(length)
+	s := int64(1)
+	if step != nil {
+		s = *step
+	}
+	if s == 0 {
+		return nil, &object.ErrorType{Message: "slice step cannot be zero"}
+	}
+
+	//step为正数时下标范围是 [0, n]，为负数时是 [-1, n-1]，-1表示第一个元素之前
+	lower, upper := int64(0), n
+	if s < 0 {
+		lower, upper = -1, n-1
+	}
+	clamp := func(bound *int64, def int64) int64 {
+		if bound == nil {
+			return def
+		}
+		v := *bound
+		if v < 0 {
+			v += n
+		}
+		return max(lower, min(v, upper))
+	}
+	var from, to int64
+	if s > 0 {
+		from, to = clamp(start, lower), clamp(stop, upper)
+	} else {
+		from, to = clamp(start, upper), clamp(stop, lower)
+	}
+
+	indexes := []int64{}
+	for i := from; (s > 0 && i < to) || (s < 0 && i > to); i += s {
+		indexes = append(indexes, i)
+	}
+	return indexes, nil
+}
@@ -0,0 +1,134 @@
+package evaluator
+
+import (
+	"fmt"
+	"interpreter/ast"
+	"interpreter/object"
+	"sort"
+)
+
+// 循环本身的值为null，每次迭代都在新的作用域中执行循环体，闭包捕获的是当次迭代的变量
+func evalWhileStatement(node *ast.WhileStatement, env *object.Environment) object.Object {
+	for {
+		cond := Eval(node.Condition, env)
+		if isAbrupt(cond) {
+			return cond
+		}
+		if !isTruthy(cond) {
+			return NULL
+		}
+		if result, stop := evalLoopBody(node.Body, object.NewEnvironment(env)); stop {
+			return result
+		}
+	}
+}
+
+/*
+for (x in iterable) 依次绑定数组的元素、哈希的键、字符串的字符、整数n对应的0到n-1
+for (a, b in iterable) 绑定数组的下标和元素、哈希的键和值、字符串的下标和字符
+*/
+func evalForStatement(node *ast.ForStatement, env *object.Environment) object.Object {
+	iterable := Eval(node.Iterable, env)
+	if isAbrupt(iterable) {
+		return iterable
+	}
+
+	var keys, values []object.Object
+	switch it := iterable.(type) {
+	case *object.Array:
+		values = it.Elements
+		keys = indexObjects(len(values))
+	case *object.Hash:
+		for _, pair := range sortedPairs(it) {
+			keys = append(keys, pair.Key)
+			values = append(values, pair.Value)
+		}
+		//单个循环变量时，哈希绑定的是键
+		if len(node.Variables) == 1 {
+			values = keys
+		}
+	case *object.String:
+		for _, ch := range it.Value {
+			values = append(values, &object.String{Value: string(ch)})
+		}
+		keys = indexObjects(len(values))
+	case *object.Interger:
+		if len(node.Variables) == 2 {
+			return &object.ErrorType{Message: "cannot use two loop variables with INTEGER"}
+		}
+		if it.Value < 0 {
+			return &object.ErrorType{Message: fmt.Sprintf("negative loop count: %d", it.Value)}
+		}
+		for i := int64(0); i < it.Value; i++ {
+			loopEnv := object.NewEnvironment(env)
+			loopEnv.Set(node.Variables[0].Value, &object.Interger{Value: i})
+			if result, stop := evalLoopBody(node.Body, loopEnv); stop {
+				return result
+			}
+		}
+		return NULL
+	default:
+		return &object.ErrorType{Message: fmt.Sprintf("%s is not iterable", iterable.Type())}
+	}
+
+	for i := range values {
+		loopEnv := object.NewEnvironment(env)
+		if len(node.Variables) == 2 {
+			loopEnv.Set(node.Variables[0].Value, keys[i])
+			loopEnv.Set(node.Variables[1].Value, values[i])
+		} else {
+			loopEnv.Set(node.Variables[0].Value, values[i])
+		}
+		if result, stop := evalLoopBody(node.Body, loopEnv); stop {
+			return result
+		}
+	}
+	return NULL
+}
+
+// 执行一次循环体，返回值stop表示循环需要结束，此时result为循环的结果
+func evalLoopBody(body *ast.BlockStatement, env *object.Environment) (object.Object, bool) {
+	result := Eval(body, env)
+	if result == nil {
+		return nil, false
+	}
+	switch result.Type() {
+	case object.BREAK_OBJ:
+		return NULL, true
+	case object.RETURN_OBJ, object.ERROR_OBJ:
+		return result, true
+	}
+	return nil, false
+}
+
+func indexObjects(n int) []object.Object {
+	indexes := make([]object.Object, n)
+	for i := range indexes {
+		indexes[i] = &object.Interger{Value: int64(i)}
+	}
+	return indexes
+}
+
+// 哈希本身是无序的，按键排序后遍历，保证每次遍历的顺序相同
+func sortedPairs(hash *object.Hash) []object.HashPair {
+	pairs := make([]object.HashPair, 0, len(hash.Pairs))
+	for _, pair := range hash.Pairs {
+		pairs = append(pairs, pair)
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		a, b := pairs[i].Key, pairs[j].Key
+		if a.Type() != b.Type() {
+			return a.Type() < b.Type()
+		}
+		switch a := a.(type) {
+		case *object.Interger:
+			return a.Value < b.(*object.Interger).Value
+		case *object.String:
+			return a.Value < b.(*object.String).Value
+		case *object.BooleanType:
+			return !a.Value && b.(*object.BooleanType).Value
+		}
+		return false
+	})
+	return pairs
+}
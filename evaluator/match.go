@@ -0,0 +1,102 @@
+package evaluator
+
+import (
+	"interpreter/ast"
+	"interpreter/object"
+)
+
+/*
+依次尝试match的各个分支，分支在新的作用域中绑定模式中的变量并对guard和body求值
+没有分支匹配时结果为null，与没有else的if一致
+*/
+func evalMatchExpression(node *ast.MatchExpression, env *object.Environment) object.Object {
+	subject := Eval(node.Subject, env)
+	if isAbrupt(subject) {
+		return subject
+	}
+	for _, arm := range node.Arms {
+		armEnv := object.NewEnvironment(env)
+		if !matchPattern(arm.Pattern, subject, armEnv) {
+			continue
+		}
+		if arm.Guard != nil {
+			cond := Eval(arm.Guard, armEnv)
+			if isAbrupt(cond) {
+				return cond
+			}
+			if !isTruthy(cond) {
+				continue
+			}
+		}
+		result := Eval(arm.Body, armEnv)
+		if result == nil {
+			return NULL
+		}
+		return result
+	}
+	return NULL
+}
+
+/*
+判断value是否匹配模式，匹配时把模式中的变量绑定到env中
+字面量与值相等时匹配，整数与浮点数按数值比较
+数组模式没有 ... 时长度必须相等；哈希模式中的key必须存在且对应的值匹配，多余的key不影响匹配
+*/
+func matchPattern(pattern ast.Expression, value object.Object, env *object.Environment) bool {
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		if pattern.Value != "_" {
+			env.Set(pattern.Value, value)
+		}
+		return true
+	case *ast.ArrayPattern:
+		arr, ok := value.(*object.Array)
+		if !ok {
+			return false
+		}
+		if len(arr.Elements) < len(pattern.Elements) {
+			return false
+		}
+		if pattern.Rest == nil && !pattern.Open && len(arr.Elements) != len(pattern.Elements) {
+			return false
+		}
+		for i, element := range pattern.Elements {
+			if !matchPattern(element, arr.Elements[i], env) {
+				return false
+			}
+		}
+		if pattern.Rest != nil {
+			rest := make([]object.Object, len(arr.Elements)-len(pattern.Elements))
+			copy(rest, arr.Elements[len(pattern.Elements):])
+			env.Set(pattern.Rest.Value, &object.Array{Elements: rest})
+		}
+		return true
+	case *ast.HashPattern:
+		hash, ok := value.(*object.Hash)
+		if !ok {
+			return false
+		}
+		used := map[object.HashKey]bool{}
+		for _, field := range pattern.Fields {
+			key := (&object.String{Value: field.Key}).HashKey()
+			pair, ok := hash.Pairs[key]
+			if !ok || !matchPattern(field.Value, pair.Value, env) {
+				return false
+			}
+			used[key] = true
+		}
+		if pattern.Rest != nil {
+			rest := &object.Hash{Pairs: make(map[object.HashKey]object.HashPair)}
+			for key, pair := range hash.Pairs {
+				if !used[key] {
+					rest.Pairs[key] = pair
+				}
+			}
+			env.Set(pattern.Rest.Value, rest)
+		}
+		return true
+	}
+	//字面量模式
+	literal := Eval(pattern, env)
+	return objectsEqual(literal, value)
+}
@@ -0,0 +1,245 @@
+package evaluator
+
+import (
+	"fmt"
+	"interpreter/object"
+	"strings"
+	"unicode/utf8"
+)
+
+/*
+对 obj.name 求值
+哈希优先查找同名的字符串键，其次查找类型上注册的方法，都找不到时返回null，与 h["name"] 一致
+其余类型只能访问方法，方法以绑定了receiver的Builtin返回，因此 value.method(args) 就是普通的调用
+对null访问字段是错误，与 null?.name 得到null不同
+*/
+func evalPropertyExpression(receiver object.Object, name string) object.Object {
+	//null上没有任何字段和方法，需要允许为null时使用 ?.
+	if receiver == NULL {
+		return &object.ErrorType{Message: fmt.Sprintf("cannot read property %s of NULL", name)}
+	}
+	if hash, ok := receiver.(*object.Hash); ok {
+		key := &object.String{Value: name}
+		if pair, ok := hash.Pairs[key.HashKey()]; ok {
+			return pair.Value
+		}
+	}
+	if method, ok := object.LookupMethod(receiver.Type(), name); ok {
+		return &object.Builtin{Fn: func(args ...object.Object) object.Object {
+			return method(receiver, args...)
+		}}
+	}
+	if receiver.Type() == object.HASH_OBJ {
+		return NULL
+	}
+	return &object.ErrorType{Message: fmt.Sprintf("%s has no method %s", receiver.Type(), name)}
+}
+
+// 内置类型的标准方法
+func init() {
+	object.RegisterMethod(object.STRING_OBJ, "len", func(receiver object.Object, args ...object.Object) object.Object {
+		if err := checkArgs("len", args, 0); err != nil {
+			return err
+		}
+		return &object.Interger{Value: int64(utf8.RuneCountInString(receiver.(*object.String).Value))}
+	})
+	object.RegisterMethod(object.STRING_OBJ, "upper", func(receiver object.Object, args ...object.Object) object.Object {
+		if err := checkArgs("upper", args, 0); err != nil {
+			return err
+		}
+		return &object.String{Value: strings.ToUpper(receiver.(*object.String).Value)}
+	})
+	object.RegisterMethod(object.STRING_OBJ, "lower", func(receiver object.Object, args ...object.Object) object.Object {
+		if err := checkArgs("lower", args, 0); err != nil {
+			return err
+		}
+		return &object.String{Value: strings.ToLower(receiver.(*object.String).Value)}
+	})
+	object.RegisterMethod(object.STRING_OBJ, "trim", func(receiver object.Object, args ...object.Object) object.Object {
+		if err := checkArgs("trim", args, 0); err != nil {
+			return err
+		}
+		return &object.String{Value: strings.TrimSpace(receiver.(*object.String).Value)}
+	})
+	object.RegisterMethod(object.STRING_OBJ, "split", func(receiver object.Object, args ...object.Object) object.Object {
+		if err := checkArgs("split", args, 1, object.STRING_OBJ); err != nil {
+			return err
+		}
+		parts := strings.Split(receiver.(*object.String).Value, args[0].(*object.String).Value)
+		elements := make([]object.Object, len(parts))
+		for i, part := range parts {
+			elements[i] = &object.String{Value: part}
+		}
+		return &object.Array{Elements: elements}
+	})
+	object.RegisterMethod(object.STRING_OBJ, "contains", func(receiver object.Object, args ...object.Object) object.Object {
+		if err := checkArgs("contains", args, 1, object.STRING_OBJ); err != nil {
+			return err
+		}
+		return nativeBooleanObject(strings.Contains(receiver.(*object.String).Value, args[0].(*object.String).Value))
+	})
+	object.RegisterMethod(object.STRING_OBJ, "replace", func(receiver object.Object, args ...object.Object) object.Object {
+		if err := checkArgs("replace", args, 2, object.STRING_OBJ, object.STRING_OBJ); err != nil {
+			return err
+		}
+		return &object.String{Value: strings.ReplaceAll(receiver.(*object.String).Value, args[0].(*object.String).Value, args[1].(*object.String).Value)}
+	})
+
+	object.RegisterMethod(object.ARRAY_OBJ, "len", func(receiver object.Object, args ...object.Object) object.Object {
+		if err := checkArgs("len", args, 0); err != nil {
+			return err
+		}
+		return &object.Interger{Value: int64(len(receiver.(*object.Array).Elements))}
+	})
+	object.RegisterMethod(object.ARRAY_OBJ, "first", func(receiver object.Object, args ...object.Object) object.Object {
+		if err := checkArgs("first", args, 0); err != nil {
+			return err
+		}
+		arr := receiver.(*object.Array)
+		if len(arr.Elements) == 0 {
+			return NULL
+		}
+		return arr.Elements[0]
+	})
+	object.RegisterMethod(object.ARRAY_OBJ, "last", func(receiver object.Object, args ...object.Object) object.Object {
+		if err := checkArgs("last", args, 0); err != nil {
+			return err
+		}
+		arr := receiver.(*object.Array)
+		if len(arr.Elements) == 0 {
+			return NULL
+		}
+		return arr.Elements[len(arr.Elements)-1]
+	})
+	//与内置函数push不同，方法push直接修改数组，并返回数组本身以便链式调用
+	object.RegisterMethod(object.ARRAY_OBJ, "push", func(receiver object.Object, args ...object.Object) object.Object {
+		if err := checkArgs("push", args, 1); err != nil {
+			return err
+		}
+		arr := receiver.(*object.Array)
+		if arr.Frozen {
+			return frozenError(arr)
+		}
+		arr.Elements = append(arr.Elements, args[0])
+		return arr
+	})
+	object.RegisterMethod(object.ARRAY_OBJ, "pop", func(receiver object.Object, args ...object.Object) object.Object {
+		if err := checkArgs("pop", args, 0); err != nil {
+			return err
+		}
+		arr := receiver.(*object.Array)
+		if arr.Frozen {
+			return frozenError(arr)
+		}
+		if len(arr.Elements) == 0 {
+			return NULL
+		}
+		last := arr.Elements[len(arr.Elements)-1]
+		arr.Elements = arr.Elements[:len(arr.Elements)-1]
+		return last
+	})
+	object.RegisterMethod(object.ARRAY_OBJ, "join", func(receiver object.Object, args ...object.Object) object.Object {
+		if err := checkArgs("join", args, 1, object.STRING_OBJ); err != nil {
+			return err
+		}
+		parts := []string{}
+		for _, el := range receiver.(*object.Array).Elements {
+			parts = append(parts, el.Inspect())
+		}
+		return &object.String{Value: strings.Join(parts, args[0].(*object.String).Value)}
+	})
+	object.RegisterMethod(object.ARRAY_OBJ, "contains", func(receiver object.Object, args ...object.Object) object.Object {
+		if err := checkArgs("contains", args, 1); err != nil {
+			return err
+		}
+		for _, el := range receiver.(*object.Array).Elements {
+			if objectsEqual(el, args[0]) {
+				return TRUE
+			}
+		}
+		return FALSE
+	})
+
+	object.RegisterMethod(object.HASH_OBJ, "len", func(receiver object.Object, args ...object.Object) object.Object {
+		if err := checkArgs("len", args, 0); err != nil {
+			return err
+		}
+		return &object.Interger{Value: int64(len(receiver.(*object.Hash).Pairs))}
+	})
+	object.RegisterMethod(object.HASH_OBJ, "keys", func(receiver object.Object, args ...object.Object) object.Object {
+		if err := checkArgs("keys", args, 0); err != nil {
+			return err
+		}
+		keys := []object.Object{}
+		for _, pair := range sortedPairs(receiver.(*object.Hash)) {
+			keys = append(keys, pair.Key)
+		}
+		return &object.Array{Elements: keys}
+	})
+	object.RegisterMethod(object.HASH_OBJ, "values", func(receiver object.Object, args ...object.Object) object.Object {
+		if err := checkArgs("values", args, 0); err != nil {
+			return err
+		}
+		values := []object.Object{}
+		for _, pair := range sortedPairs(receiver.(*object.Hash)) {
+			values = append(values, pair.Value)
+		}
+		return &object.Array{Elements: values}
+	})
+	object.RegisterMethod(object.HASH_OBJ, "has", func(receiver object.Object, args ...object.Object) object.Object {
+		if err := checkArgs("has", args, 1); err != nil {
+			return err
+		}
+		hashable, ok := args[0].(object.Hashable)
+		if !ok {
+			return unusableHashKeyError(args[0])
+		}
+		_, ok = receiver.(*object.Hash).Pairs[hashable.HashKey()]
+		return nativeBooleanObject(ok)
+	})
+	object.RegisterMethod(object.HASH_OBJ, "delete", func(receiver object.Object, args ...object.Object) object.Object {
+		if err := checkArgs("delete", args, 1); err != nil {
+			return err
+		}
+		hashable, ok := args[0].(object.Hashable)
+		if !ok {
+			return unusableHashKeyError(args[0])
+		}
+		hash := receiver.(*object.Hash)
+		if hash.Frozen {
+			return frozenError(hash)
+		}
+		pair, ok := hash.Pairs[hashable.HashKey()]
+		if !ok {
+			return NULL
+		}
+		delete(hash.Pairs, hashable.HashKey())
+		return pair.Value
+	})
+}
+
+// 检查方法的参数个数，types依次给出前几个参数要求的类型
+func checkArgs(name string, args []object.Object, want int, types ...object.ObjectType) *object.ErrorType {
+	if len(args) != want {
+		return &object.ErrorType{Message: fmt.Sprintf("wrong number of arguments to `%s`. got=%d, want=%d", name, len(args), want)}
+	}
+	for i, tpe := range types {
+		if args[i].Type() != tpe {
+			return &object.ErrorType{Message: fmt.Sprintf("argument %d to `%s` must be %s, got %s", i+1, name, tpe, args[i].Type())}
+		}
+	}
+	return nil
+}
+
+// 值相等：可作为哈希键的值按键比较，整数与浮点数按数值比较，其余按引用比较
+func objectsEqual(a, b object.Object) bool {
+	ha, ok1 := a.(object.Hashable)
+	hb, ok2 := b.(object.Hashable)
+	if ok1 && ok2 && a.Type() == b.Type() {
+		return ha.HashKey() == hb.HashKey()
+	}
+	if isNumber(a) && isNumber(b) {
+		return toFloat(a) == toFloat(b)
+	}
+	return a == b
+}
@@ -0,0 +1,83 @@
+package evaluator
+
+import (
+	"fmt"
+	"interpreter/ast"
+	"interpreter/object"
+)
+
+/*
+按解构模式把value中的各部分绑定到env中
+数组模式没有 ...rest 时长度必须相等，有 ...rest 时数组至少要包含模式中的元素，其余元素组成新的数组
+哈希模式中的key必须存在，...rest 得到剩余键值对组成的新哈希表
+constant为true时绑定的都是常量
+*/
+func bindPattern(pattern ast.Expression, value object.Object, env *object.Environment, constant bool) *object.ErrorType {
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		return declare(env, pattern, value, constant)
+	case *ast.ArrayPattern:
+		arr, ok := value.(*object.Array)
+		if !ok {
+			return &object.ErrorType{Message: fmt.Sprintf("cannot destructure %s as array", value.Type()), Pos: pattern.Pos()}
+		}
+		if pattern.Rest == nil && len(arr.Elements) != len(pattern.Elements) {
+			return &object.ErrorType{Message: fmt.Sprintf("array pattern expects %d elements, got %d", len(pattern.Elements), len(arr.Elements)), Pos: pattern.Pos()}
+		}
+		if len(arr.Elements) < len(pattern.Elements) {
+			return &object.ErrorType{Message: fmt.Sprintf("array pattern expects at least %d elements, got %d", len(pattern.Elements), len(arr.Elements)), Pos: pattern.Pos()}
+		}
+		for i, element := range pattern.Elements {
+			if err := bindPattern(element, arr.Elements[i], env, constant); err != nil {
+				return err
+			}
+		}
+		if pattern.Rest != nil {
+			rest := make([]object.Object, len(arr.Elements)-len(pattern.Elements))
+			copy(rest, arr.Elements[len(pattern.Elements):])
+			return declare(env, pattern.Rest, &object.Array{Elements: rest}, constant)
+		}
+		return nil
+	case *ast.HashPattern:
+		hash, ok := value.(*object.Hash)
+		if !ok {
+			return &object.ErrorType{Message: fmt.Sprintf("cannot destructure %s as hash", value.Type()), Pos: pattern.Pos()}
+		}
+		used := map[object.HashKey]bool{}
+		for _, field := range pattern.Fields {
+			key := (&object.String{Value: field.Key}).HashKey()
+			pair, ok := hash.Pairs[key]
+			if !ok {
+				return &object.ErrorType{Message: fmt.Sprintf("key not found: %s", field.Key), Pos: field.Value.Pos()}
+			}
+			used[key] = true
+			if err := bindPattern(field.Value, pair.Value, env, constant); err != nil {
+				return err
+			}
+		}
+		if pattern.Rest != nil {
+			rest := &object.Hash{Pairs: make(map[object.HashKey]object.HashPair)}
+			for key, pair := range hash.Pairs {
+				if !used[key] {
+					rest.Pairs[key] = pair
+				}
+			}
+			return declare(env, pattern.Rest, rest, constant)
+		}
+		return nil
+	}
+	return &object.ErrorType{Message: fmt.Sprintf("invalid pattern: %s", pattern.String())}
+}
+
+// 在当前作用域中定义变量或常量，同一作用域中已有的常量不能被重新定义
+func declare(env *object.Environment, name *ast.Identifier, value object.Object, constant bool) *object.ErrorType {
+	if env.IsLocalConst(name.Value) {
+		return &object.ErrorType{Message: fmt.Sprintf("cannot redeclare constant %s", name.Value), Pos: name.Pos()}
+	}
+	if constant {
+		env.SetConst(name.Value, value)
+	} else {
+		env.Set(name.Value, value)
+	}
+	return nil
+}
@@ -1,48 +1,168 @@
 package lexer
 
 import (
+	"fmt"
 	"interpreter/token"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 type Lexer struct {
 	_input        string
+	_filename     string
 	_position     int
 	_readPosition int
-	_ch           byte
+	_ch           rune            //当前字符，按UTF-8解码
+	_line         int             //_ch所在的行
+	_column       int             //_ch所在的列，按字符而非字节计数
+	_templates    []int           //每层未结束的 ${ 内部尚未闭合的 { 数量，用于判断 } 是否结束插值
+	_lastLine     int             //上一个token结束时所在的行
+	_lastType     token.TokenType //上一个token的类型，用于区分 x--y 中的 -- ，见isMinusNegative
 }
 
 func New(input string) *Lexer {
-	lexer := &Lexer{_input: input}
+	return NewWithFilename("", input)
+}
+
+// NewWithFilename 创建一个词法分析器，filename会记录在每个token的位置信息中
+func NewWithFilename(filename string, input string) *Lexer {
+	lexer := &Lexer{_input: input, _filename: filename, _line: 1, _lastLine: 1}
 	lexer.readChar()
 
 	return lexer
 }
 
 func (l *Lexer) readChar() {
+	if l._ch == '\n' {
+		l._line += 1
+		l._column = 1
+	} else {
+		l._column += 1
+	}
+	width := 1
 	if l._readPosition >= len(l._input) {
 		//注意！这里一定是0而不是'0'
 		l._ch = 0
 	} else {
-		l._ch = l._input[l._readPosition]
+		l._ch, width = utf8.DecodeRuneInString(l._input[l._readPosition:])
 	}
 	l._position = l._readPosition
-	l._readPosition += 1
+	l._readPosition += width
 }
 
 var keywords = map[string]token.TokenType{
-	"let":    token.LET,
-	"fn":     token.FUNCTION,
-	"if":     token.IF,
-	"return": token.RETURN,
-	"true":   token.TRUE,
-	"false":  token.FALSE,
-	"else":   token.ELSE,
+	"let":      token.LET,
+	"fn":       token.FUNCTION,
+	"if":       token.IF,
+	"return":   token.RETURN,
+	"true":     token.TRUE,
+	"false":    token.FALSE,
+	"else":     token.ELSE,
+	"while":    token.WHILE,
+	"for":      token.FOR,
+	"in":       token.IN,
+	"break":    token.BREAK,
+	"continue": token.CONTINUE,
+	"match":    token.MATCH,
+	"null":     token.NULL,
+	"const":    token.CONST,
 }
 
-func newToken(tpe token.TokenType, ch byte) token.Token {
+func newToken(tpe token.TokenType, ch rune) token.Token {
 	return token.Token{Type: tpe, Literal: string(ch)}
 }
+
+// 当前字符_ch的位置
+func (l *Lexer) curPosition() token.Position {
+	return token.Position{Filename: l._filename, Offset: l._position, Line: l._line, Column: l._column}
+}
+
+// NextToken 返回下一个token，并记录其起止位置
+// 跳过的注释会保存在token的Comments中
 func (l *Lexer) NextToken() token.Token {
+	var comments []string
+	for {
+		l.skipWhitespace()
+		if l._ch != '/' {
+			break
+		}
+		if l.peekChar() == '/' {
+			comments = append(comments, l.readLineComment())
+		} else if l.peekChar() == '*' {
+			pos := l.curPosition()
+			comment, ok := l.readBlockComment()
+			if !ok {
+				return token.Token{Type: token.ILLEGAL, Literal: "unterminated block comment", Pos: pos, End: l.curPosition()}
+			}
+			comments = append(comments, comment)
+		} else {
+			break
+		}
+	}
+	pos := l.curPosition()
+	tok := l.nextToken()
+	tok.Pos = pos
+	tok.End = l.curPosition()
+	tok.Comments = comments
+	tok.NewlineBefore = pos.Line > l._lastLine
+	l._lastLine = tok.End.Line
+	l._lastType = tok.Type
+	return tok
+}
+
+// 运算符后紧跟=时组成复合赋值运算符，例如 += <<=
+func (l *Lexer) withAssign(tok token.Token, assign token.TokenType) token.Token {
+	if l.peekChar() == '=' {
+		l.readChar()
+		return token.Token{Type: assign, Literal: tok.Literal + "="}
+	}
+	return tok
+}
+func (l *Lexer) skipWhitespace() {
+	for l._ch == ' ' || l._ch == '\t' || l._ch == '\n' || l._ch == '\r' {
+		l.readChar()
+	}
+}
+func (l *Lexer) peekChar() rune {
+	if l._readPosition >= len(l._input) {
+		return 0
+	}
+	ch, _ := utf8.DecodeRuneInString(l._input[l._readPosition:])
+	return ch
+}
+
+// 读取 // 注释直到行尾，不包含换行符
+func (l *Lexer) readLineComment() string {
+	position := l._position
+	for l._ch != '\n' && l._ch != 0 {
+		l.readChar()
+	}
+	return l._input[position:l._position]
+}
+
+// 读取 /* */ 注释，支持嵌套，未闭合时返回false
+func (l *Lexer) readBlockComment() (string, bool) {
+	position := l._position
+	depth := 0
+	for l._ch != 0 {
+		if l._ch == '/' && l.peekChar() == '*' {
+			depth += 1
+			l.readChar()
+		} else if l._ch == '*' && l.peekChar() == '/' {
+			depth -= 1
+			l.readChar()
+			if depth == 0 {
+				l.readChar()
+				return l._input[position:l._position], true
+			}
+		}
+		l.readChar()
+	}
+	return l._input[position:l._position], false
+}
+func (l *Lexer) nextToken() token.Token {
 	var tok token.Token
 	switch l._ch {
 	case ':':
@@ -53,6 +173,10 @@ func (l *Lexer) NextToken() token.Token {
 			l.readChar()
 			tok.Literal = "=="
 			tok.Type = token.EQ
+		} else if l._ch == '>' {
+			l.readChar()
+			tok.Literal = "=>"
+			tok.Type = token.ARROW
 		} else {
 			tok = newToken(token.ASSIGN, '=')
 		}
@@ -64,19 +188,92 @@ func (l *Lexer) NextToken() token.Token {
 	case ')':
 		tok = newToken(token.RPAREN, l._ch)
 	case '{':
+		if n := len(l._templates); n > 0 {
+			l._templates[n-1] += 1
+		}
 		tok = newToken(token.LBRACE, l._ch)
 	case '}':
+		if n := len(l._templates); n > 0 {
+			if l._templates[n-1] == 0 {
+				//结束插值，继续读取字符串剩余的部分
+				l._templates = l._templates[:n-1]
+				return l.readStringToken(token.TEMPLATE_TAIL, token.TEMPLATE_MIDDLE)
+			}
+			l._templates[n-1] -= 1
+		}
 		tok = newToken(token.RBRACE, l._ch)
 	case ',':
 		tok = newToken(token.COMMA, l._ch)
+	case '.':
+		if strings.HasPrefix(l._input[l._position:], "...") {
+			l.readChar()
+			l.readChar()
+			tok = token.Token{Type: token.ELLIPSIS, Literal: "..."}
+		} else if l.isLeadingDotFloat() {
+			tok.Literal, tok.Type = l.readNum()
+			return tok
+		} else {
+			tok = newToken(token.DOT, l._ch)
+		}
 	case '+':
-		tok = newToken(token.PLUS, l._ch)
+		if l.peekChar() == '+' {
+			l.readChar()
+			tok = token.Token{Type: token.INCREMENT, Literal: "++"}
+		} else {
+			tok = l.withAssign(newToken(token.PLUS, l._ch), token.PLUS_ASSIGN)
+		}
 	case '-':
-		tok = newToken(token.MINUS, l._ch)
+		if l.peekChar() == '-' && !l.isMinusNegative() {
+			l.readChar()
+			tok = token.Token{Type: token.DECREMENT, Literal: "--"}
+		} else {
+			tok = l.withAssign(newToken(token.MINUS, l._ch), token.MINUS_ASSIGN)
+		}
 	case '*':
-		tok = newToken(token.ASTERISK, l._ch)
+		if l.peekChar() == '*' {
+			l.readChar()
+			tok = l.withAssign(token.Token{Type: token.POWER, Literal: "**"}, token.POWER_ASSIGN)
+		} else {
+			tok = l.withAssign(newToken(token.ASTERISK, l._ch), token.ASTERISK_ASSIGN)
+		}
+	case '%':
+		tok = l.withAssign(newToken(token.PERCENT, l._ch), token.PERCENT_ASSIGN)
+	case '&':
+		if l.peekChar() == '&' {
+			l.readChar()
+			tok = token.Token{Type: token.AND, Literal: "&&"}
+		} else {
+			tok = l.withAssign(newToken(token.BIT_AND, l._ch), token.BIT_AND_ASSIGN)
+		}
+	case '|':
+		if l.peekChar() == '|' {
+			l.readChar()
+			tok = token.Token{Type: token.OR, Literal: "||"}
+		} else {
+			tok = l.withAssign(newToken(token.BIT_OR, l._ch), token.BIT_OR_ASSIGN)
+		}
+	case '?':
+		rest := l._input[l._position:]
+		switch {
+		case strings.HasPrefix(rest, "??"):
+			l.readChar()
+			tok = token.Token{Type: token.NULLISH, Literal: "??"}
+		//cond ?.5 : 1 中的 ?. 是条件表达式的 ? 加上浮点数 .5
+		case strings.HasPrefix(rest, "?.") && !(len(rest) > 2 && isNum(rune(rest[2]))):
+			l.readChar()
+			tok = token.Token{Type: token.OPTIONAL_DOT, Literal: "?."}
+		case strings.HasPrefix(rest, "?["):
+			l.readChar()
+			tok = token.Token{Type: token.OPTIONAL_LBRACKET, Literal: "?["}
+		default:
+			tok = newToken(token.QUESTION, l._ch)
+		}
+	case '^':
+		tok = l.withAssign(newToken(token.BIT_XOR, l._ch), token.BIT_XOR_ASSIGN)
+	case '~':
+		tok = newToken(token.TILDE, l._ch)
 	case '/':
-		tok = newToken(token.SLASH, l._ch)
+		tok = l.withAssign(newToken(token.SLASH, l._ch), token.SLASH_ASSIGN)
 	case '[':
 		tok = newToken(token.LBRACKET, l._ch)
 	case ']':
@@ -87,6 +284,15 @@ func (l *Lexer) NextToken() token.Token {
 			tok.Literal = "<="
 			tok.Type = token.LT_OR_EQ
 			l.readChar()
+		} else if l._ch == '<' {
+			tok.Literal = "<<"
+			tok.Type = token.SHIFT_LEFT
+			l.readChar()
+			if l._ch == '=' {
+				tok.Literal = "<<="
+				tok.Type = token.SHIFT_LEFT_ASSIGN
+				l.readChar()
+			}
 		} else {
 			tok = newToken(token.LT, '<')
 		}
@@ -97,6 +303,15 @@ func (l *Lexer) NextToken() token.Token {
 			tok.Literal = ">="
 			tok.Type = token.GT_OR_EQ
 			l.readChar()
+		} else if l._ch == '>' {
+			tok.Literal = ">>"
+			tok.Type = token.SHIFT_RIGHT
+			l.readChar()
+			if l._ch == '=' {
+				tok.Literal = ">>="
+				tok.Type = token.SHIFT_RIGHT_ASSIGN
+				l.readChar()
+			}
 		} else {
 			tok = newToken(token.GT, '>')
 		}
@@ -115,18 +330,22 @@ func (l *Lexer) NextToken() token.Token {
 			tok.Type = token.BANG
 		}
 		return tok
-	case ' ':
-		l.readChar()
-		return l.NextToken()
-	case '\n':
-		l.readChar()
-		return l.NextToken()
-	case '\t':
-		l.readChar()
-		return l.NextToken()
 	case '"':
+		if strings.HasPrefix(l._input[l._position:], `"""`) {
+			str, ok := l.readTripleQuotedString()
+			if !ok {
+				return token.Token{Type: token.ILLEGAL, Literal: "unterminated triple-quoted string"}
+			}
+			return token.Token{Type: token.STRING, Literal: str}
+		}
+		return l.readStringToken(token.STRING, token.TEMPLATE_HEAD)
+	case '`':
+		str, ok := l.readRawString()
+		if !ok {
+			return token.Token{Type: token.ILLEGAL, Literal: "unterminated raw string"}
+		}
 		tok.Type = token.STRING
-		tok.Literal = l.readString()
+		tok.Literal = str
 
 	default:
 		//将关键字存储在一个map中，如果查找到key，则返回，查不到则设置为非法
@@ -140,8 +359,7 @@ func (l *Lexer) NextToken() token.Token {
 			return tok
 		} else {
 			if isNum(l._ch) {
-				tok.Literal = l.readNum()
-				tok.Type = token.INT
+				tok.Literal, tok.Type = l.readNum()
 				return tok
 			}
 			tok = newToken(token.ILLEGAL, l._ch)
@@ -154,33 +372,302 @@ func (l *Lexer) NextToken() token.Token {
 func (l *Lexer) readIden() string {
 	position := l._position
 	if isLetter(l._ch) {
-		for isLetter(l._ch) {
+		for isLetter(l._ch) || unicode.IsDigit(l._ch) {
 			l.readChar()
 		}
 	}
 	return l._input[position:l._position]
 }
-func (l *Lexer) readNum() string {
+
+/*
+读取整数或浮点数
+整数支持 0x 0o 0b 前缀，数字之间可以用下划线分隔，例如 0xFF 1_000_000
+小数点后必须紧跟数字才视为浮点数，例如 1.5，而 1. 只读取1，小数点前的0可以省略，例如 .5
+指数部分为 e 或 E 加上可选的正负号和数字，例如 1e-9 2.5E3
+数字是否合法以及是否越界由语法分析器检查
+*/
+func (l *Lexer) readNum() (string, token.TokenType) {
 	position := l._position
-	for isNum(l._ch) {
-		//fmt.Printf("char : %c isNum:%v", l._ch, isNum(l._ch))
+	tpe := token.TokenType(token.INT)
+	if l._ch == '0' && strings.ContainsRune("xXoObB", l.peekChar()) {
+		l.readChar()
+		l.readChar()
+		for isHex(l._ch) || l._ch == '_' {
+			l.readChar()
+		}
+		return l._input[position:l._position], tpe
+	}
+	l.readDigits()
+	if l._ch == '.' && isNum(l.peekChar()) {
+		tpe = token.FLOAT
 		l.readChar()
+		l.readDigits()
+	}
+	if (l._ch == 'e' || l._ch == 'E') && l.isExponentStart() {
+		tpe = token.FLOAT
+		l.readChar()
+		if l._ch == '+' || l._ch == '-' {
+			l.readChar()
+		}
+		l.readDigits()
 	}
 
-	return l._input[position:l._position]
+	return l._input[position:l._position], tpe
 }
-func isLetter(ch byte) bool {
-	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_'
+func (l *Lexer) readDigits() {
+	for isNum(l._ch) || l._ch == '_' {
+		l.readChar()
+	}
+}
+
+// 判断e之后是否为合法的指数，即数字或正负号加数字
+func (l *Lexer) isExponentStart() bool {
+	next := l.peekChar()
+	if isNum(next) {
+		return true
+	}
+	if next != '+' && next != '-' {
+		return false
+	}
+	return l._readPosition+1 < len(l._input) && isNum(rune(l._input[l._readPosition+1]))
+}
+
+// 当前为 . 时，判断是否为省略了0的浮点数 .5 ，紧跟在标识符、数字和右括号之后时是字段访问，例如 a.1 中的 .
+func (l *Lexer) isLeadingDotFloat() bool {
+	if !isNum(l.peekChar()) {
+		return false
+	}
+	if l._position == 0 {
+		return true
+	}
+	prev := l._input[l._position-1]
+	return prev < utf8.RuneSelf && !isLetter(rune(prev)) && !isNum(rune(prev)) && !strings.ContainsRune(")]}\"`", rune(prev))
+}
+
+// 可以结束一个操作数的token，它们之后的 -- 是后缀自减或者减号
+var operandEnds = map[token.TokenType]bool{
+	token.IDENT: true, token.INT: true, token.FLOAT: true, token.STRING: true, token.TEMPLATE_TAIL: true,
+	token.TRUE: true, token.FALSE: true, token.NULL: true, token.RPAREN: true, token.RBRACKET: true,
+}
+
+/*
+当前为 -- 时，判断它是否是减号加上负号，例如 x--y 和 x--1 等价于 x - (-y) 和 x - (-1)
+-- 紧跟在操作数之后，并且后面紧跟着下一个操作数时才是减号，x-- 和 x-- - y 中的 -- 仍然是后缀自减
+--5 这样不能自减的目标由语法分析器当作两个负号处理
+*/
+func (l *Lexer) isMinusNegative() bool {
+	if !operandEnds[l._lastType] || l._readPosition+1 >= len(l._input) {
+		return false
+	}
+	next := rune(l._input[l._readPosition+1])
+	return next >= utf8.RuneSelf || isLetter(next) || isNum(next) || strings.ContainsRune("([{\"`-!~.", next)
+}
+
+// 标识符可以由任意Unicode字母和下划线开头，后续字符还可以是数字
+func isLetter(ch rune) bool {
+	return unicode.IsLetter(ch) || ch == '_'
 }
-func isNum(ch byte) bool {
+
+// 数字字面量只接受ASCII数字
+func isNum(ch rune) bool {
 	return '0' <= ch && ch <= '9'
 }
-func (l *Lexer) readString() string {
+
+/*
+读取一段字符串，_ch为开头的 " 或结束插值的 }
+字符串以 " 结束时返回plain类型的token，遇到 ${ 时返回interp类型的token，之后是插值表达式的token
+例如 "a${x}b${y}c" 会被识别为 TEMPLATE_HEAD(a) IDENT(x) TEMPLATE_MIDDLE(b) IDENT(y) TEMPLATE_TAIL(c)
+*/
+func (l *Lexer) readStringToken(plain token.TokenType, interp token.TokenType) token.Token {
+	str, reason, isInterp := l.readString()
+	if isInterp {
+		l._templates = append(l._templates, 0)
+	} else {
+		l.readChar()
+	}
+	if reason != "" {
+		return token.Token{Type: token.ILLEGAL, Literal: reason}
+	}
+	if isInterp {
+		return token.Token{Type: interp, Literal: str}
+	}
+	return token.Token{Type: plain, Literal: str}
+}
+
+/*
+读取字符串并处理转义，返回解码后的值
+出错时第二个返回值为错误原因，此时仍会读到字符串结尾，以便后续token能正常识别
+遇到 ${ 时停止，第三个返回值为true，此时 ${ 已被读取
+支持的转义: \n \t \r \\ \" \$ \xNN \u{N...}
+字符串不能跨行，遇到换行或文件结尾视为未闭合
+*/
+func (l *Lexer) readString() (string, string, bool) {
+	var out strings.Builder
+	reason := ""
+	l.readChar()
+	for l._ch != '"' {
+		if l._ch == 0 || l._ch == '\n' {
+			return out.String(), "unterminated string", false
+		}
+		if l._ch == '$' && l.peekChar() == '{' {
+			l.readChar()
+			l.readChar()
+			return out.String(), reason, true
+		}
+		if l._ch != '\\' {
+			//直接拷贝原始字节，非法的UTF-8序列也原样保留
+			out.WriteString(l._input[l._position:l._readPosition])
+			l.readChar()
+			continue
+		}
+		l.readChar()
+		if err := l.readEscape(&out); err != "" && reason == "" {
+			reason = err
+		}
+	}
+	return out.String(), reason, false
+}
+
+// 读取反斜杠之后的转义序列，写入out，返回错误原因
+func (l *Lexer) readEscape(out *strings.Builder) string {
+	switch l._ch {
+	case 'n':
+		out.WriteByte('\n')
+	case 't':
+		out.WriteByte('\t')
+	case 'r':
+		out.WriteByte('\r')
+	case '\\':
+		out.WriteByte('\\')
+	case '"':
+		out.WriteByte('"')
+	case '$':
+		out.WriteByte('$')
+	case 'x':
+		l.readChar()
+		digits := l.readHexDigits(2)
+		if len(digits) != 2 {
+			return "invalid escape sequence \\x" + digits + ": want 2 hex digits"
+		}
+		value, _ := strconv.ParseUint(digits, 16, 8)
+		out.WriteByte(byte(value))
+		return ""
+	case 'u':
+		l.readChar()
+		if l._ch != '{' {
+			return "invalid escape sequence \\u: want \\u{...}"
+		}
+		l.readChar()
+		digits := l.readHexDigits(6)
+		if l._ch != '}' || len(digits) == 0 {
+			return "invalid escape sequence \\u{" + digits + ": want 1 to 6 hex digits and }"
+		}
+		l.readChar()
+		value, _ := strconv.ParseUint(digits, 16, 32)
+		if !utf8.ValidRune(rune(value)) {
+			return "invalid escape sequence \\u{" + digits + "}: not a valid code point"
+		}
+		out.WriteRune(rune(value))
+		return ""
+	case 0, '\n':
+		//交给readString报告未闭合
+		return ""
+	default:
+		reason := fmt.Sprintf("invalid escape sequence \\%c", l._ch)
+		l.readChar()
+		return reason
+	}
+	l.readChar()
+	return ""
+}
+
+// 读取反引号字符串，可以跨行，不处理转义，其中的\r会被去掉
+func (l *Lexer) readRawString() (string, bool) {
 	l.readChar()
-	start := l._position
-	for l._ch != '"' && l._ch != 0 {
+	position := l._position
+	for l._ch != '`' {
+		if l._ch == 0 {
+			return "", false
+		}
+		l.readChar()
+	}
+	return strings.ReplaceAll(l._input[position:l._position], "\r", ""), true
+}
+
+// 读取三引号字符串，与反引号字符串一样不处理转义，并去掉公共缩进，见dedent
+func (l *Lexer) readTripleQuotedString() (string, bool) {
+	for i := 0; i < 3; i++ {
+		l.readChar()
+	}
+	position := l._position
+	for !strings.HasPrefix(l._input[l._position:], `"""`) {
+		if l._ch == 0 {
+			return "", false
+		}
+		l.readChar()
+	}
+	str := l._input[position:l._position]
+	for i := 0; i < 3; i++ {
 		l.readChar()
 	}
-	str := l._input[start:l._position]
-	return str
+	return dedent(strings.ReplaceAll(str, "\r", "")), true
+}
+
+/*
+去掉多行字符串的公共缩进
+开头 """ 之后紧跟的空行和结尾 """ 之前只有空白的行会被去掉，例如
+
+	let s = """
+	    a
+	      b
+	    """;
+
+s的值为 "a\n  b"
+*/
+func dedent(str string) string {
+	lines := strings.Split(str, "\n")
+	if len(lines) > 1 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	if len(lines) > 1 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	indent := ""
+	first := true
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		lineIndent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		if first {
+			indent = lineIndent
+			first = false
+			continue
+		}
+		for !strings.HasPrefix(lineIndent, indent) {
+			indent = indent[:len(indent)-1]
+		}
+	}
+
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			lines[i] = ""
+		} else {
+			lines[i] = line[len(indent):]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// 最多读取max个十六进制字符
+func (l *Lexer) readHexDigits(max int) string {
+	position := l._position
+	for l._position-position < max && isHex(l._ch) {
+		l.readChar()
+	}
+	return l._input[position:l._position]
+}
+func isHex(ch rune) bool {
+	return isNum(ch) || 'a' <= ch && ch <= 'f' || 'A' <= ch && ch <= 'F'
 }
@@ -14,7 +14,7 @@ let add = fn(x, y) {
 };
 
 let result = add(five, ten);
-!-/*5;
+!-/ *5;
 5 < 10 > 5;
 
 if (5 < 10) {
@@ -139,3 +139,465 @@ if (5 < 10) {
 		}
 	}
 }
+
+func TestTokenPosition(t *testing.T) {
+	input := "let x = 5;\n  x + 10;"
+
+	tests := []struct {
+		expectedLiteral string
+		line            int
+		column          int
+		offset          int
+		endColumn       int
+	}{
+		{"let", 1, 1, 0, 4},
+		{"x", 1, 5, 4, 6},
+		{"=", 1, 7, 6, 8},
+		{"5", 1, 9, 8, 10},
+		{";", 1, 10, 9, 11},
+		{"x", 2, 3, 13, 4},
+		{"+", 2, 5, 15, 6},
+		{"10", 2, 7, 17, 9},
+		{";", 2, 9, 19, 10},
+	}
+
+	lexer := NewWithFilename("test.mata", input)
+	for i, tt := range tests {
+		tok := lexer.NextToken()
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - Literal wrong. expected=%q,got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+		if tok.Pos.Line != tt.line || tok.Pos.Column != tt.column || tok.Pos.Offset != tt.offset {
+			t.Errorf("tests[%d] - Pos wrong. expected=%d:%d(%d),got=%d:%d(%d)",
+				i, tt.line, tt.column, tt.offset, tok.Pos.Line, tok.Pos.Column, tok.Pos.Offset)
+		}
+		if tok.End.Column != tt.endColumn {
+			t.Errorf("tests[%d] - End column wrong. expected=%d,got=%d", i, tt.endColumn, tok.End.Column)
+		}
+		if tok.Pos.Filename != "test.mata" {
+			t.Errorf("tests[%d] - Filename wrong. got=%q", i, tok.Pos.Filename)
+		}
+	}
+}
+
+func TestComments(t *testing.T) {
+	input := `// leading comment
+let x = 5; // trailing
+/* block /* nested */ comment */
+x / 2;
+/* unterminated`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.LET, "let"},
+		{token.IDENT, "x"},
+		{token.ASSIGN, "="},
+		{token.INT, "5"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.SLASH, "/"},
+		{token.INT, "2"},
+		{token.SEMICOLON, ";"},
+		{token.ILLEGAL, "unterminated block comment"},
+		{token.EOF, ""},
+	}
+
+	lexer := New(input)
+	var toks []token.Token
+	for i, tt := range tests {
+		tok := lexer.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - TokenType wrong. expected=%q,got=%q value=%s", i, tt.expectedType, tok.Type, tok.Literal)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - Literal wrong. expected=%q,got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+		toks = append(toks, tok)
+	}
+
+	if len(toks[0].Comments) != 1 || toks[0].Comments[0] != "// leading comment" {
+		t.Errorf("comments of let wrong. got=%q", toks[0].Comments)
+	}
+	if len(toks[5].Comments) != 2 || toks[5].Comments[1] != "/* block /* nested */ comment */" {
+		t.Errorf("comments of x wrong. got=%q", toks[5].Comments)
+	}
+	if toks[9].Pos.Line != 5 || toks[9].Pos.Column != 1 {
+		t.Errorf("position of unterminated comment wrong. got=%s", toks[9].Pos)
+	}
+}
+
+func TestStringEscapes(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{`"a\nb\tc\r"`, token.STRING, "a\nb\tc\r"},
+		{`"say \"hi\" \\ done"`, token.STRING, `say "hi" \ done`},
+		{`"\x41\x7a"`, token.STRING, "Az"},
+		{`"\u{4e2d}\u{1F600}"`, token.STRING, "中😀"},
+		{`"abc`, token.ILLEGAL, "unterminated string"},
+		{"\"abc\nlet", token.ILLEGAL, "unterminated string"},
+		{`"bad \q escape"`, token.ILLEGAL, `invalid escape sequence \q`},
+		{`"\x4"`, token.ILLEGAL, `invalid escape sequence \x4: want 2 hex digits`},
+		{`"\u{110000}"`, token.ILLEGAL, `invalid escape sequence \u{110000}: not a valid code point`},
+		{`"\u0041"`, token.ILLEGAL, `invalid escape sequence \u: want \u{...}`},
+	}
+
+	for i, tt := range tests {
+		tok := New(tt.input).NextToken()
+		if tok.Type != tt.expectedType {
+			t.Errorf("tests[%d] - TokenType wrong. expected=%q,got=%q value=%s", i, tt.expectedType, tok.Type, tok.Literal)
+			continue
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Errorf("tests[%d] - Literal wrong. expected=%q,got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+
+	//错误的转义之后仍能继续识别后面的token
+	lexer := New(`"\q" 5`)
+	lexer.NextToken()
+	if tok := lexer.NextToken(); tok.Type != token.INT {
+		t.Errorf("token after bad escape wrong. got=%q", tok.Type)
+	}
+}
+
+func TestUnicodeIdentifiers(t *testing.T) {
+	input := "let 名字 = \"你好\"; café + x1;"
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+		column          int
+	}{
+		{token.LET, "let", 1},
+		{token.IDENT, "名字", 5},
+		{token.ASSIGN, "=", 8},
+		{token.STRING, "你好", 10},
+		{token.SEMICOLON, ";", 14},
+		{token.IDENT, "café", 16},
+		{token.PLUS, "+", 21},
+		{token.IDENT, "x1", 23},
+		{token.SEMICOLON, ";", 25},
+		{token.EOF, "", 26},
+	}
+
+	lexer := New(input)
+	for i, tt := range tests {
+		tok := lexer.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - TokenType wrong. expected=%q,got=%q value=%s", i, tt.expectedType, tok.Type, tok.Literal)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - Literal wrong. expected=%q,got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+		if tok.Pos.Column != tt.column {
+			t.Errorf("tests[%d] - Column wrong. expected=%d,got=%d", i, tt.column, tok.Pos.Column)
+		}
+	}
+}
+
+func TestNumbers(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{"123", token.INT, "123"},
+		{"1.5", token.FLOAT, "1.5"},
+		{"0.25", token.FLOAT, "0.25"},
+		{"1e-9", token.FLOAT, "1e-9"},
+		{"2.5E+3", token.FLOAT, "2.5E+3"},
+		{"3e10", token.FLOAT, "3e10"},
+		{"1.", token.INT, "1"},
+		{"4e", token.INT, "4"},
+		{"5e+", token.INT, "5"},
+		{".5", token.FLOAT, ".5"},
+		{".25e2", token.FLOAT, ".25e2"},
+	}
+
+	for i, tt := range tests {
+		tok := New(tt.input).NextToken()
+		if tok.Type != tt.expectedType {
+			t.Errorf("tests[%d] - TokenType wrong. expected=%q,got=%q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Errorf("tests[%d] - Literal wrong. expected=%q,got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestIntegerPrefixesAndSeparators(t *testing.T) {
+	input := "0xFF 0o17 0b1010 1_000_000 0x_dead_BEEF 1_000.5 0b12"
+	expected := []string{"0xFF", "0o17", "0b1010", "1_000_000", "0x_dead_BEEF", "1_000.5", "0b12"}
+
+	lexer := New(input)
+	for i, literal := range expected {
+		tok := lexer.NextToken()
+		if tok.Literal != literal {
+			t.Errorf("tests[%d] - Literal wrong. expected=%q,got=%q", i, literal, tok.Literal)
+		}
+	}
+}
+
+func TestOperators(t *testing.T) {
+	input := `a % b ** c && d || e & f | g ^ h << i >> j ~k <= l`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.IDENT, "a"}, {token.PERCENT, "%"},
+		{token.IDENT, "b"}, {token.POWER, "**"},
+		{token.IDENT, "c"}, {token.AND, "&&"},
+		{token.IDENT, "d"}, {token.OR, "||"},
+		{token.IDENT, "e"}, {token.BIT_AND, "&"},
+		{token.IDENT, "f"}, {token.BIT_OR, "|"},
+		{token.IDENT, "g"}, {token.BIT_XOR, "^"},
+		{token.IDENT, "h"}, {token.SHIFT_LEFT, "<<"},
+		{token.IDENT, "i"}, {token.SHIFT_RIGHT, ">>"},
+		{token.IDENT, "j"}, {token.TILDE, "~"},
+		{token.IDENT, "k"}, {token.LT_OR_EQ, "<="},
+		{token.IDENT, "l"}, {token.EOF, ""},
+	}
+
+	lexer := New(input)
+	for i, tt := range tests {
+		tok := lexer.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - token wrong. expected=%q(%q),got=%q(%q)", i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestCompoundAssignOperators(t *testing.T) {
+	input := `+= -= *= /= %= **= &= |= ^= <<= >>= && ||`
+	expected := []token.TokenType{
+		token.PLUS_ASSIGN, token.MINUS_ASSIGN, token.ASTERISK_ASSIGN, token.SLASH_ASSIGN,
+		token.PERCENT_ASSIGN, token.POWER_ASSIGN, token.BIT_AND_ASSIGN, token.BIT_OR_ASSIGN,
+		token.BIT_XOR_ASSIGN, token.SHIFT_LEFT_ASSIGN, token.SHIFT_RIGHT_ASSIGN, token.AND, token.OR,
+	}
+
+	lexer := New(input)
+	for i, tt := range expected {
+		tok := lexer.NextToken()
+		if tok.Type != tt || tok.Literal != string(tt) {
+			t.Fatalf("tests[%d] - token wrong. expected=%q,got=%q(%q)", i, tt, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestStringInterpolation(t *testing.T) {
+	input := `"Hello ${name}, you have ${len({"a": 1})} items" "\${x}" "${a}"`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.TEMPLATE_HEAD, "Hello "},
+		{token.IDENT, "name"},
+		{token.TEMPLATE_MIDDLE, ", you have "},
+		{token.IDENT, "len"},
+		{token.LPAREN, "("},
+		{token.LBRACE, "{"},
+		{token.STRING, "a"},
+		{token.COLON, ":"},
+		{token.INT, "1"},
+		{token.RBRACE, "}"},
+		{token.RPAREN, ")"},
+		{token.TEMPLATE_TAIL, " items"},
+		{token.STRING, "${x}"},
+		{token.TEMPLATE_HEAD, ""},
+		{token.IDENT, "a"},
+		{token.TEMPLATE_TAIL, ""},
+		{token.EOF, ""},
+	}
+
+	lexer := New(input)
+	for i, tt := range tests {
+		tok := lexer.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - token wrong. expected=%q(%q),got=%q(%q)", i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestRawAndMultilineStrings(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{"`a\\d+\\n${x}`", token.STRING, `a\d+\n${x}`},
+		{"`line1\r\nline2`", token.STRING, "line1\nline2"},
+		{`"""abc"""`, token.STRING, "abc"},
+		{"\"\"\"\n    SELECT *\n      FROM t\n\n    WHERE a = \"x\"\n    \"\"\"", token.STRING, "SELECT *\n  FROM t\n\nWHERE a = \"x\""},
+		{"\"\"\"\n\tkeep \\n raw\n\t\"\"\"", token.STRING, "keep \\n raw"},
+		{`""`, token.STRING, ""},
+		{"`never closed", token.ILLEGAL, "unterminated raw string"},
+		{`"""never closed`, token.ILLEGAL, "unterminated triple-quoted string"},
+	}
+
+	for i, tt := range tests {
+		tok := New(tt.input).NextToken()
+		if tok.Type != tt.expectedType {
+			t.Errorf("tests[%d] - TokenType wrong. expected=%q,got=%q value=%q", i, tt.expectedType, tok.Type, tok.Literal)
+			continue
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Errorf("tests[%d] - Literal wrong. expected=%q,got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+
+	//跨行的字符串之后位置信息仍然正确
+	lexer := New("`a\nb` x")
+	lexer.NextToken()
+	if tok := lexer.NextToken(); tok.Pos.Line != 2 || tok.Pos.Column != 4 {
+		t.Errorf("position after raw string wrong. got=%s", tok.Pos)
+	}
+}
+func TestNewlineBefore(t *testing.T) {
+	//跨行的注释也算作换行
+	input := "let a = 1\nlet b = /* x\ny */ 2 a\n\n(b)"
+	expected := []struct {
+		literal       string
+		newlineBefore bool
+	}{
+		{"let", false}, {"a", false}, {"=", false}, {"1", false},
+		{"let", true}, {"b", false}, {"=", false}, {"2", true}, {"a", false},
+		{"(", true}, {"b", false}, {")", false},
+	}
+	lexer := New(input)
+	for i, e := range expected {
+		tok := lexer.NextToken()
+		if tok.Literal != e.literal || tok.NewlineBefore != e.newlineBefore {
+			t.Errorf("tests[%d] - expected=%q(%t),got=%q(%t)", i, e.literal, e.newlineBefore, tok.Literal, tok.NewlineBefore)
+		}
+	}
+}
+func TestLoopKeywords(t *testing.T) {
+	expected := []token.TokenType{token.WHILE, token.FOR, token.IN, token.BREAK, token.CONTINUE, token.IDENT, token.EOF}
+	lexer := New("while for in break continue inside")
+	for i, tpe := range expected {
+		if tok := lexer.NextToken(); tok.Type != tpe {
+			t.Errorf("tests[%d] - expected=%q,got=%q", i, tpe, tok.Type)
+		}
+	}
+}
+func TestDot(t *testing.T) {
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.IDENT, "arr"}, {token.DOT, "."}, {token.IDENT, "push"}, {token.LPAREN, "("},
+		{token.FLOAT, "1.5"}, {token.RPAREN, ")"}, {token.DOT, "."}, {token.IDENT, "len"},
+		{token.INT, "1"}, {token.DOT, "."}, {token.IDENT, "x"},
+		{token.ELLIPSIS, "..."}, {token.IDENT, "rest"}, {token.ELLIPSIS, "..."}, {token.DOT, "."},
+		{token.IDENT, "a"}, {token.DOT, "."}, {token.INT, "1"}, {token.RPAREN, ")"}, {token.DOT, "."}, {token.INT, "0"},
+		{token.LPAREN, "("}, {token.FLOAT, ".5"}, {token.RPAREN, ")"}, {token.EOF, ""},
+	}
+	lexer := New("arr.push(1.5).len 1.x ...rest .... a.1 ).0 (.5)")
+	for i, tt := range tests {
+		tok := lexer.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Errorf("tests[%d] - expected=%q(%q),got=%q(%q)", i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+}
+func TestArrow(t *testing.T) {
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.IDENT, "x"}, {token.ARROW, "=>"}, {token.IDENT, "x"},
+		{token.EQ, "=="}, {token.ASSIGN, "="}, {token.GT, ">"}, {token.EOF, ""},
+	}
+	lexer := New("x => x == = >")
+	for i, tt := range tests {
+		tok := lexer.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Errorf("tests[%d] - expected=%q(%q),got=%q(%q)", i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+}
+func TestMatchKeyword(t *testing.T) {
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.MATCH, "match"}, {token.LPAREN, "("}, {token.IDENT, "x"}, {token.RPAREN, ")"},
+		{token.IDENT, "matches"}, {token.IDENT, "_"}, {token.EOF, ""},
+	}
+	lexer := New("match (x) matches _")
+	for i, tt := range tests {
+		tok := lexer.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Errorf("tests[%d] - expected=%q(%q),got=%q(%q)", i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+}
+func TestNullOperators(t *testing.T) {
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.NULL, "null"}, {token.IDENT, "a"}, {token.NULLISH, "??"}, {token.IDENT, "b"},
+		{token.IDENT, "a"}, {token.OPTIONAL_DOT, "?."}, {token.IDENT, "b"},
+		{token.IDENT, "a"}, {token.OPTIONAL_LBRACKET, "?["}, {token.INT, "0"}, {token.RBRACKET, "]"},
+		{token.IDENT, "f"}, {token.OPTIONAL_DOT, "?."}, {token.LPAREN, "("}, {token.RPAREN, ")"},
+		{token.QUESTION, "?"}, {token.IDENT, "x"},
+		{token.IDENT, "flag"}, {token.QUESTION, "?"}, {token.FLOAT, ".5"}, {token.COLON, ":"}, {token.INT, "1"},
+		{token.EOF, ""},
+	}
+	lexer := New("null a ?? b a?.b a?[0] f?.() ? x flag ?.5 : 1")
+	for i, tt := range tests {
+		tok := lexer.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Errorf("tests[%d] - expected=%q(%q),got=%q(%q)", i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+}
+func TestConstKeyword(t *testing.T) {
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.CONST, "const"}, {token.IDENT, "x"}, {token.ASSIGN, "="}, {token.INT, "1"},
+		{token.IDENT, "constant"}, {token.EOF, ""},
+	}
+	lexer := New("const x = 1 constant")
+	for i, tt := range tests {
+		tok := lexer.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Errorf("tests[%d] - expected=%q(%q),got=%q(%q)", i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+}
+func TestIncrementOperators(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []token.TokenType
+	}{
+		{"i++ --j", []token.TokenType{token.IDENT, token.INCREMENT, token.DECREMENT, token.IDENT}},
+		{"+ += - -=", []token.TokenType{token.PLUS, token.PLUS_ASSIGN, token.MINUS, token.MINUS_ASSIGN}},
+		{"x--y", []token.TokenType{token.IDENT, token.MINUS, token.MINUS, token.IDENT}},
+		{"x--1", []token.TokenType{token.IDENT, token.MINUS, token.MINUS, token.INT}},
+		{"a[0]--(b)", []token.TokenType{token.IDENT, token.LBRACKET, token.INT, token.RBRACKET, token.MINUS, token.MINUS, token.LPAREN, token.IDENT, token.RPAREN}},
+		{"x-- - y", []token.TokenType{token.IDENT, token.DECREMENT, token.MINUS, token.IDENT}},
+		{"x--;", []token.TokenType{token.IDENT, token.DECREMENT, token.SEMICOLON}},
+		{"--5", []token.TokenType{token.DECREMENT, token.INT}},
+		{"y = --x", []token.TokenType{token.IDENT, token.ASSIGN, token.DECREMENT, token.IDENT}},
+	}
+	for _, tt := range tests {
+		lexer := New(tt.input)
+		for i, expected := range append(tt.expected, token.EOF) {
+			tok := lexer.NextToken()
+			if tok.Type != expected {
+				t.Errorf("input %q: tokens[%d] - expected=%q,got=%q(%q)", tt.input, i, expected, tok.Type, tok.Literal)
+			}
+		}
+	}
+}
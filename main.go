@@ -2,12 +2,20 @@ package main
 
 import (
 	"fmt"
+	"interpreter/evaluator"
+	"interpreter/lexer"
+	"interpreter/object"
+	"interpreter/parser"
 	"interpreter/repl"
 	"os"
 	"os/user"
 )
 
 func main() {
+	//带文件名参数时直接执行脚本，否则进入repl
+	if len(os.Args) > 1 {
+		os.Exit(runFile(os.Args[1]))
+	}
 	user, err := user.Current()
 	if err != nil {
 		panic(err)
@@ -18,3 +26,26 @@ func main() {
 	repl.Start(os.Stdin, os.Stdout)
 
 }
+
+// 执行脚本文件，返回进程退出码
+func runFile(filename string) int {
+	source, err := os.ReadFile(filename)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	p := parser.New(lexer.NewWithFilename(filename, string(source)))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		for _, msg := range p.Errors() {
+			fmt.Fprintln(os.Stderr, msg)
+		}
+		return 1
+	}
+	result := evaluator.Eval(program, object.NewEnvironment(nil))
+	if result != nil && result.Type() == object.ERROR_OBJ {
+		fmt.Fprintln(os.Stderr, result.Inspect())
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,22 @@
+package object
+
+// MethodFunction 是按类型注册的方法，receiver为 . 左侧的值
+type MethodFunction func(receiver Object, args ...Object) Object
+
+// 每种类型的方法表，value.name(args) 按value的类型在这里查找name
+var methods = map[ObjectType]map[string]MethodFunction{}
+
+// RegisterMethod 为某个类型注册方法，同名方法会被覆盖
+// 宿主程序可以在执行脚本前调用它来扩展内置类型
+func RegisterMethod(tpe ObjectType, name string, fn MethodFunction) {
+	if methods[tpe] == nil {
+		methods[tpe] = make(map[string]MethodFunction)
+	}
+	methods[tpe][name] = fn
+}
+
+// LookupMethod 查找某个类型的方法
+func LookupMethod(tpe ObjectType, name string) (MethodFunction, bool) {
+	fn, ok := methods[tpe][name]
+	return fn, ok
+}
@@ -5,14 +5,19 @@ import (
 	"fmt"
 	"hash/fnv"
 	"interpreter/ast"
+	"interpreter/token"
+	"strconv"
 	"strings"
 )
 
 const (
 	INTEGER_OBJ  = `INTEGER`
+	FLOAT_OBJ    = `FLOAT`
 	BOOLEAN_OBJ  = `BOOLEAN`
 	NULL_OBJ     = `NULL`
 	RETURN_OBJ   = `RETURN`
+	BREAK_OBJ    = `BREAK`
+	CONTINUE_OBJ = `CONTINUE`
 	ERROR_OBJ    = `ERROR`
 	FUNCTION_OBJ = `FUNCTION`
 	STRING_OBJ   = `STRING`
@@ -27,22 +32,19 @@ type Object interface {
 	Inspect() string
 }
 type Environment struct {
-	_store map[string]Object
-	_args  map[string]Object
-	_outer *Environment
+	_store  map[string]Object
+	_consts map[string]bool //_store中以const定义的名字
+	_outer  *Environment
 }
 
 func NewEnvironment(outer *Environment) *Environment {
-	res := &Environment{_store: make(map[string]Object), _outer: outer, _args: make(map[string]Object)}
+	res := &Environment{_store: make(map[string]Object), _outer: outer}
 
 	return res
 }
 
 func (e *Environment) Get(key string) (Object, bool) {
 	obj, ok := e._store[key]
-	if !ok && len(e._args) != 0 {
-		obj, ok = e._args[key]
-	}
 	if !ok && e._outer != nil {
 		obj, ok = e._outer.Get(key)
 	}
@@ -51,14 +53,46 @@ func (e *Environment) Get(key string) (Object, bool) {
 }
 func (e *Environment) Set(key string, value Object) Object {
 	e._store[key] = value
+	delete(e._consts, key)
 	return value
 }
-func (e *Environment) SetArgs(key string, value Object) Object {
-	e._args[key] = value
+
+// SetConst 定义常量，常量不能被赋值
+func (e *Environment) SetConst(key string, value Object) Object {
+	if e._consts == nil {
+		e._consts = make(map[string]bool)
+	}
+	e._store[key] = value
+	e._consts[key] = true
 	return value
 }
-func (e *Environment) IsNull() bool {
-	return len(e._args) == 0
+
+// IsConst 与Get一样沿作用域链向外查找key，判断找到的绑定是否为常量
+func (e *Environment) IsConst(key string) bool {
+	if _, ok := e._store[key]; ok {
+		return e._consts[key]
+	}
+	if e._outer != nil {
+		return e._outer.IsConst(key)
+	}
+	return false
+}
+
+// IsLocalConst 判断key是否为当前作用域中定义的常量，不查找外层作用域
+func (e *Environment) IsLocalConst(key string) bool {
+	return e._consts[key]
+}
+
+// Assign 修改已定义的变量，沿作用域链向外查找，找不到时返回false
+func (e *Environment) Assign(key string, value Object) bool {
+	if _, ok := e._store[key]; ok {
+		e._store[key] = value
+		return true
+	}
+	if e._outer != nil {
+		return e._outer.Assign(key, value)
+	}
+	return false
 }
 
 type Interger struct {
@@ -68,6 +102,20 @@ type Interger struct {
 func (i *Interger) Inspect() string  { return fmt.Sprintf("%d", i.Value) }
 func (i *Interger) Type() ObjectType { return INTEGER_OBJ }
 
+type Float struct {
+	Value float64
+}
+
+// 整数值的浮点数也带上小数点，以便和整数区分，例如 2.0
+func (f *Float) Inspect() string {
+	str := strconv.FormatFloat(f.Value, 'g', -1, 64)
+	if !strings.ContainsAny(str, ".eIN") {
+		str += ".0"
+	}
+	return str
+}
+func (f *Float) Type() ObjectType { return FLOAT_OBJ }
+
 type BooleanType struct {
 	Value bool
 }
@@ -87,15 +135,35 @@ type ReturnType struct {
 func (rt *ReturnType) Inspect() string  { return rt.Value.Inspect() }
 func (rt *ReturnType) Type() ObjectType { return RETURN_OBJ }
 
+// break和continue与return一样，作为信号沿语句块向外传递，直到被循环接收
+type BreakType struct{}
+
+func (bt *BreakType) Inspect() string  { return "break" }
+func (bt *BreakType) Type() ObjectType { return BREAK_OBJ }
+
+type ContinueType struct{}
+
+func (ct *ContinueType) Inspect() string  { return "continue" }
+func (ct *ContinueType) Type() ObjectType { return CONTINUE_OBJ }
+
 type ErrorType struct {
 	Message string
+	Pos     token.Position //产生错误的位置
 }
 
-func (et *ErrorType) Inspect() string  { return "ERROR: " + et.Message }
+func (et *ErrorType) Inspect() string {
+	if et.Pos.IsValid() {
+		return "ERROR: " + et.Pos.String() + ": " + et.Message
+	}
+	return "ERROR: " + et.Message
+}
 func (et *ErrorType) Type() ObjectType { return ERROR_OBJ }
 
+// Environment为定义函数时所在的作用域，调用时在它之上创建新的作用域，因此函数是闭包
 type Function struct {
 	Parameters  []*ast.Identifier
+	Defaults    []ast.Expression
+	Rest        *ast.Identifier
 	Body        *ast.BlockStatement
 	Environment *Environment
 }
@@ -104,8 +172,15 @@ func (f *Function) Inspect() string {
 	var out bytes.Buffer
 
 	params := []string{}
-	for _, p := range f.Parameters {
-		params = append(params, p.String())
+	for i, p := range f.Parameters {
+		if i < len(f.Defaults) && f.Defaults[i] != nil {
+			params = append(params, p.String()+" = "+f.Defaults[i].String())
+		} else {
+			params = append(params, p.String())
+		}
+	}
+	if f.Rest != nil {
+		params = append(params, "..."+f.Rest.String())
 	}
 	out.WriteString("fn")
 	out.WriteString("(")
@@ -125,15 +200,21 @@ func (s *String) Inspect() string  { return s.Value }
 func (s *String) Type() ObjectType { return STRING_OBJ }
 
 type BuiltinFunction func(args ...Object) Object
+
+// 可以接收关键字参数的内置函数，kwargs中为按名字传入的参数
+type BuiltinKeywordFunction func(args []Object, kwargs map[string]Object) Object
 type Builtin struct {
-	Fn BuiltinFunction
+	Fn        BuiltinFunction
+	KeywordFn BuiltinKeywordFunction //不为nil时调用它而不是Fn，没有它的内置函数不接受关键字参数
 }
 
 func (b *Builtin) Type() ObjectType { return BUILTIN_OBJ }
 func (b *Builtin) Inspect() string  { return "builtin function" }
 
+// Frozen为true时数组不能被修改，见内置函数freeze
 type Array struct {
 	Elements []Object
+	Frozen   bool
 }
 
 func (ar *Array) Type() ObjectType { return ARRAY_OBJ }
@@ -152,7 +233,8 @@ func (ar *Array) Inspect() string {
 
 // 由于在repl中需要打印key value，如果使用map[HashKey]Object，就会打印出HashKey,而Hash值毫无意义
 type Hash struct {
-	Pairs map[HashKey]HashPair
+	Pairs  map[HashKey]HashPair
+	Frozen bool
 }
 
 func (h *Hash) Type() ObjectType { return HASH_OBJ }
@@ -162,6 +244,11 @@ type HashKey struct {
 	Value uint64
 }
 
+// Hashable 可以作为哈希表key的对象
+type Hashable interface {
+	HashKey() HashKey
+}
+
 func (b *BooleanType) HashKey() HashKey {
 	var value uint64
 	if b.Value {
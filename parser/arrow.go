@@ -0,0 +1,83 @@
+package parser
+
+import (
+	"fmt"
+	"interpreter/ast"
+	"interpreter/token"
+)
+
+/*
+解析箭头函数 x => body 、 (a, b = 1, ...rest) => body ，进入时当前token为 =>
+params为 => 之前已经按表达式解析的参数，body为表达式时相当于只包含这个表达式的语句块，即返回它的值
+结果与 fn(...) {...} 一样是 *ast.FunctionLiteral
+*/
+func (p *Parser) parseArrowFunction(start token.Token, params []ast.Expression) ast.Expression {
+	lit := &ast.FunctionLiteral{
+		Token:      token.Token{Type: token.FUNCTION, Literal: "fn", Pos: start.Pos, End: start.End},
+		Parameters: []*ast.Identifier{},
+		Defaults:   []ast.Expression{},
+	}
+	for i, param := range params {
+		switch param := param.(type) {
+		case *ast.Identifier:
+			if len(lit.Defaults) > 0 && lit.Defaults[len(lit.Defaults)-1] != nil {
+				p.addError(param.Token, fmt.Sprintf("parameter %s without default value follows parameter with default value", param.Value))
+				return nil
+			}
+			lit.Parameters = append(lit.Parameters, param)
+			lit.Defaults = append(lit.Defaults, nil)
+			continue
+		case *ast.InfixExpression:
+			if ident, ok := param.Left.(*ast.Identifier); ok && param.Operator == "=" {
+				lit.Parameters = append(lit.Parameters, ident)
+				lit.Defaults = append(lit.Defaults, param.Right)
+				continue
+			}
+		case *ast.SpreadExpression:
+			if ident, ok := param.Value.(*ast.Identifier); ok && i == len(params)-1 {
+				lit.Rest = ident
+				continue
+			}
+		}
+		p.reportError(&ParseError{
+			Pos:      param.Pos(),
+			Expected: token.IDENT,
+			Message:  fmt.Sprintf("invalid arrow function parameter: %s", param.String()),
+		})
+		return nil
+	}
+
+	//函数体内的break和continue不能跳出函数外的循环
+	loopDepth := p._loopDepth
+	p._loopDepth = 0
+	p.pushScope()
+	defer func() {
+		p.popScope()
+		p._loopDepth = loopDepth
+	}()
+	p.declareParameters(lit)
+
+	lit.Body = p.parseArrowBody()
+	return lit
+}
+
+// 当前token所在的括号层数
+func (p *Parser) depth() int {
+	return p._braceDepth + p._parenDepth
+}
+
+// match guard中与guard同一层的 => 是分支的开始而不是箭头函数，例如 x if x > y => x ，括号内的箭头函数不受影响
+func (p *Parser) arrowAllowed(depth int) bool {
+	return depth != p._guardDepth
+}
+
+// 解析 => 之后的 { ... } 语句块或单个表达式，单个表达式包装为只有一条语句的语句块，它的值就是语句块的值
+func (p *Parser) parseArrowBody() *ast.BlockStatement {
+	p.nextToken()
+	if p.curTokenIs(token.LBRACE) {
+		return p.parseBlockStatement()
+	}
+	tok := p._curToken
+	body := p.parseExpression(LOWEST)
+	return &ast.BlockStatement{Token: tok, Statements: []ast.Statement{&ast.ExpressionStatement{Token: tok, Expression: body}}}
+}
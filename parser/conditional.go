@@ -0,0 +1,50 @@
+package parser
+
+import (
+	"interpreter/ast"
+	"interpreter/token"
+)
+
+/*
+cond ? a : b ，右结合，a ? b : c ? d : e 等价于 a ? b : (c ? d : e)
+优先级低于 ?? 和 || ，高于赋值，因此 x = a ?? b ? c : d 等价于 x = ((a ?? b) ? c : d)
+注意 ?[ 是可选下标，条件后面紧跟数组时需要空格：cond ? [1] : [2]
+*/
+func (p *Parser) parseConditionalExpression(condition ast.Expression) ast.Expression {
+	expression := &ast.ConditionalExpression{Token: p._curToken, Condition: condition}
+
+	p.nextToken()
+	expression.Consequence = p.parseExpression(LOWEST)
+	if !p.expectedPeek(token.COLON) {
+		return nil
+	}
+	p.nextToken()
+	expression.Alternative = p.parseExpression(TERNARY - 1)
+
+	return expression
+}
+
+/*
+解析 < > <= >= ，连续出现时组成 *ast.ComparisonChain ，例如 0 <= x < 10
+只有一个比较运算符时仍然是普通的 *ast.InfixExpression ，(a < b) < c 这样加了括号的也不会连在一起
+*/
+func (p *Parser) parseComparisonExpression(left ast.Expression) ast.Expression {
+	chain := &ast.ComparisonChain{Token: p._curToken, Operands: []ast.Expression{left}}
+	for {
+		chain.Operators = append(chain.Operators, p._curToken.Literal)
+		p.nextToken()
+		chain.Operands = append(chain.Operands, p.parseExpression(LESSGRATER))
+		if !isComparison(p._peekToken.Type) {
+			break
+		}
+		p.nextToken()
+	}
+	if len(chain.Operators) == 1 {
+		return &ast.InfixExpression{Token: chain.Token, Operator: chain.Operators[0], Left: left, Right: chain.Operands[1]}
+	}
+	return chain
+}
+
+func isComparison(tpe token.TokenType) bool {
+	return tpe == token.LT || tpe == token.GT || tpe == token.LT_OR_EQ || tpe == token.GT_OR_EQ
+}
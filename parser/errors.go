@@ -0,0 +1,90 @@
+package parser
+
+import (
+	"interpreter/token"
+)
+
+// ParseError 一条语法错误
+type ParseError struct {
+	Pos      token.Position //出错的位置
+	Expected string         //期望的token或语法成分，没有明确期望时为空
+	Actual   token.Token    //实际遇到的token
+	Message  string
+}
+
+// Error 返回 line:column: message 形式的错误信息
+func (e *ParseError) Error() string {
+	return e.Pos.String() + ": " + e.Message
+}
+
+// 出错后可以重新开始解析的语句关键字
+var statementKeywords = map[token.TokenType]bool{
+	token.LET:      true,
+	token.CONST:    true,
+	token.RETURN:   true,
+	token.WHILE:    true,
+	token.FOR:      true,
+	token.BREAK:    true,
+	token.CONTINUE: true,
+}
+
+/*
+记录一条错误并进入panic模式
+panic模式下不再记录新的错误，直到当前语句被丢弃并在同步点重新开始解析，
+这样一个错误只会被报告一次，而不会引起后续一连串无意义的错误
+*/
+func (p *Parser) reportError(err *ParseError) {
+	if p._panicking {
+		return
+	}
+	p._panicking = true
+	p._errors = append(p._errors, err)
+}
+func (p *Parser) addError(tok token.Token, msg string) {
+	p.reportError(&ParseError{Pos: tok.Pos, Actual: tok, Message: msg})
+}
+
+/*
+跳过出错语句剩余的token，并退出panic模式
+停在当前token为 ; 或所在语句块的 } 处，或下一个token为 } 、语句关键字、EOF或在新的一行处（括号内的换行除外），
+depth为出错语句开始时的 { 层数，语句内部的 { } 会被整体跳过，
+例如 for (x of arr) { x } 中循环体的 } 和 let {1: a} = xs 中模式的 }
+调用方照常调用nextToken后即可开始解析下一条语句
+*/
+func (p *Parser) synchronize(depth int) {
+	p._panicking = false
+	for !p.curTokenIs(token.EOF) {
+		after := p._braceDepth //包含当前token在内的 { 层数
+		switch p._curToken.Type {
+		case token.LBRACE:
+			after++
+		case token.RBRACE:
+			if p._braceDepth <= depth {
+				return
+			}
+			after--
+		case token.SEMICOLON:
+			if p._braceDepth == depth {
+				return
+			}
+		}
+		if after == depth && (p.peekTokenIs(token.RBRACE) || p.peekTokenIs(token.EOF) || (p._peekToken.NewlineBefore && !p.inParens()) || statementKeywords[p._peekToken.Type]) {
+			return
+		}
+		p.nextToken()
+	}
+}
+
+// ParseErrors 返回所有语法错误
+func (p *Parser) ParseErrors() []*ParseError {
+	return p._errors
+}
+
+// Errors 返回所有语法错误的文本形式
+func (p *Parser) Errors() []string {
+	errors := []string{}
+	for _, err := range p._errors {
+		errors = append(errors, err.Error())
+	}
+	return errors
+}
@@ -0,0 +1,59 @@
+package parser
+
+import (
+	"fmt"
+	"interpreter/ast"
+	"interpreter/token"
+)
+
+/*
+++x --x ，目标以PREFIX优先级解析，因此 ++a[0] 和 ++h.n 修改的是下标和字段
+--5 、--(a + b) 这样不能自减的目标与原来一样是两个负号，即 -(-5)
+*/
+func (p *Parser) parsePrefixIncrement() ast.Expression {
+	expression := &ast.IncrementExpression{Token: p._curToken, Operator: p._curToken.Literal}
+	p.nextToken()
+	expression.Target = p.parseExpression(PREFIX)
+	if expression.Target == nil {
+		return nil
+	}
+	if expression.Operator == "--" && !isIncrementTarget(expression.Target) {
+		tok := expression.Token
+		first := token.Token{Type: token.MINUS, Literal: "-", Pos: tok.Pos, End: tok.Pos}
+		first.End.Column++
+		first.End.Offset++
+		second := token.Token{Type: token.MINUS, Literal: "-", Pos: first.End, End: tok.End}
+		return &ast.PrefixExpression{Token: first, Operator: "-", Right: &ast.PrefixExpression{Token: second, Operator: "-", Right: expression.Target}}
+	}
+	p.checkIncrementTarget(expression.Operator, expression.Target)
+	return expression
+}
+
+// x++ x-- ，优先级与调用相同，因此 -x++ 等价于 -(x++)
+func (p *Parser) parsePostfixIncrement(left ast.Expression) ast.Expression {
+	expression := &ast.IncrementExpression{Token: p._curToken, Operator: p._curToken.Literal, Target: left, Postfix: true}
+	p.checkIncrementTarget(expression.Operator, left)
+	return expression
+}
+
+// 与赋值一样，只有变量、下标和字段可以自增自减，并且不能修改常量
+func (p *Parser) checkIncrementTarget(operator string, target ast.Expression) {
+	if !isIncrementTarget(target) {
+		p.reportError(&ParseError{
+			Pos:     target.Pos(),
+			Message: fmt.Sprintf("invalid %s operand: %s", operator, target.String()),
+		})
+		return
+	}
+	if ident, ok := target.(*ast.Identifier); ok {
+		p.checkAssign(ident)
+	}
+}
+
+func isIncrementTarget(target ast.Expression) bool {
+	switch target.(type) {
+	case *ast.Identifier, *ast.IndexExpression, *ast.PropertyExpression:
+		return true
+	}
+	return false
+}
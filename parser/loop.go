@@ -0,0 +1,96 @@
+package parser
+
+import (
+	"fmt"
+	"interpreter/ast"
+	"interpreter/token"
+)
+
+// while (cond) { body }
+func (p *Parser) parseWhileStatement() ast.Statement {
+	stmt := &ast.WhileStatement{Token: p._curToken}
+
+	if !p.expectedPeek(token.LPAREN) {
+		return nil
+	}
+	p.nextToken()
+	stmt.Condition = p.parseExpression(LOWEST)
+	if !p.expectedPeek(token.RPAREN) {
+		return nil
+	}
+	if !p.expectedPeek(token.LBRACE) {
+		return nil
+	}
+	stmt.Body = p.parseLoopBody(nil)
+	p.expectStatementEnd()
+
+	return stmt
+}
+
+// for (x in iterable) { body } 或 for (a, b in iterable) { body }
+func (p *Parser) parseForStatement() ast.Statement {
+	stmt := &ast.ForStatement{Token: p._curToken}
+
+	if !p.expectedPeek(token.LPAREN) {
+		return nil
+	}
+	if !p.expectedPeek(token.IDENT) {
+		return nil
+	}
+	stmt.Variables = append(stmt.Variables, &ast.Identifier{Token: p._curToken, Value: p._curToken.Literal})
+	if p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+		if !p.expectedPeek(token.IDENT) {
+			return nil
+		}
+		stmt.Variables = append(stmt.Variables, &ast.Identifier{Token: p._curToken, Value: p._curToken.Literal})
+	}
+	if !p.expectedPeek(token.IN) {
+		return nil
+	}
+	p.nextToken()
+	stmt.Iterable = p.parseExpression(LOWEST)
+	if !p.expectedPeek(token.RPAREN) {
+		return nil
+	}
+	if !p.expectedPeek(token.LBRACE) {
+		return nil
+	}
+	stmt.Body = p.parseLoopBody(stmt.Variables)
+	p.expectStatementEnd()
+
+	return stmt
+}
+
+// 每次迭代都在新的作用域中执行循环体，variables为其中的循环变量
+func (p *Parser) parseLoopBody(variables []*ast.Identifier) *ast.BlockStatement {
+	p._loopDepth++
+	p.pushScope()
+	defer func() {
+		p.popScope()
+		p._loopDepth--
+	}()
+	for _, variable := range variables {
+		p.declare(variable, false)
+	}
+	return p.parseBlockStatement()
+}
+
+// break 和 continue 只能出现在循环体中
+func (p *Parser) parseLoopControlStatement() ast.Statement {
+	tok := p._curToken
+	if p._loopDepth == 0 {
+		p.reportError(&ParseError{
+			Pos:     tok.Pos,
+			Actual:  tok,
+			Message: fmt.Sprintf("%s outside loop", tok.Literal),
+		})
+		return nil
+	}
+	p.expectStatementEnd()
+
+	if tok.Type == token.BREAK {
+		return &ast.BreakStatement{Token: tok}
+	}
+	return &ast.ContinueStatement{Token: tok}
+}
@@ -0,0 +1,121 @@
+package parser
+
+import (
+	"fmt"
+	"interpreter/ast"
+	"interpreter/token"
+)
+
+/*
+match (subject) { 0 => "zero", [x, ...] if x > 0 => x, {"type": "point", x, y} => { x + y }, _ => -1 }
+分支之间用逗号分隔，分支在新的一行开始或前一个分支以 { ... } 结尾时逗号可以省略
+*/
+func (p *Parser) parseMatchExpression() ast.Expression {
+	expression := &ast.MatchExpression{Token: p._curToken}
+
+	if !p.expectedPeek(token.LPAREN) {
+		return nil
+	}
+	p.nextToken()
+	expression.Subject = p.parseExpression(LOWEST)
+	if !p.expectedPeek(token.RPAREN) {
+		return nil
+	}
+	if !p.expectedPeek(token.LBRACE) {
+		return nil
+	}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+		arm := p.parseMatchArm()
+		//分支体中出错时停止解析，交给synchronize跳过整条语句，否则可能把后面的语句当作分支解析
+		if arm == nil || p._panicking {
+			return nil
+		}
+		expression.Arms = append(expression.Arms, arm)
+
+		if p.peekTokenIs(token.COMMA) {
+			p.nextToken()
+			continue
+		}
+		//{ ... } 语句块之后的逗号也可以省略
+		if !p.peekTokenIs(token.RBRACE) && !p._peekToken.NewlineBefore && arm.Body.Token.Type != token.LBRACE {
+			p.reportError(&ParseError{
+				Pos:      p._peekToken.Pos,
+				Expected: token.COMMA,
+				Actual:   p._peekToken,
+				Message:  fmt.Sprintf("expected , or } after match arm,but got:%s instead", p._peekToken.Type),
+			})
+			return nil
+		}
+	}
+	if !p.expectedPeek(token.RBRACE) {
+		return nil
+	}
+	expression.RBrace = p._curToken
+	p._blockEnd = p._curToken.Pos
+
+	return expression
+}
+
+// pattern [if guard] => body ，每个分支在新的作用域中绑定模式中的变量
+func (p *Parser) parseMatchArm() *ast.MatchArm {
+	arm := &ast.MatchArm{Pattern: p.parseMatchPattern()}
+	if arm.Pattern == nil {
+		return nil
+	}
+	p.pushScope()
+	defer p.popScope()
+	p.declarePattern(arm.Pattern, false)
+
+	if p.peekTokenIs(token.IF) {
+		p.nextToken()
+		p.nextToken()
+		guardDepth := p._guardDepth
+		p._guardDepth = p.depth()
+		arm.Guard = p.parseExpression(LOWEST)
+		p._guardDepth = guardDepth
+	}
+	if !p.expectedPeek(token.ARROW) {
+		return nil
+	}
+	arm.Body = p.parseArrowBody()
+
+	return arm
+}
+
+/*
+解析match分支中的模式
+_ 匹配任意值，其他标识符匹配任意值并绑定到该名字，字面量按值比较，[...] 和 {...} 按结构匹配，其中的元素也是模式
+*/
+func (p *Parser) parseMatchPattern() ast.Expression {
+	switch p._curToken.Type {
+	case token.IDENT:
+		return &ast.Identifier{Token: p._curToken, Value: p._curToken.Literal}
+	case token.INT:
+		return p.parseIntergerLiberal()
+	case token.FLOAT:
+		return p.parseFloatLiteral()
+	case token.STRING:
+		return p.parseStringLiteral()
+	case token.TRUE, token.FALSE:
+		return p.parseBoolean()
+	case token.NULL:
+		return p.parseNullLiteral()
+	case token.MINUS:
+		if p.peekTokenIs(token.INT) || p.peekTokenIs(token.FLOAT) {
+			return p.parsePrefixExpression()
+		}
+	case token.LBRACKET:
+		return p.parseArrayPattern(true)
+	case token.LBRACE:
+		return p.parseHashPattern(true)
+	}
+	p.reportError(&ParseError{
+		Pos:      p._curToken.Pos,
+		Expected: "pattern",
+		Actual:   p._curToken,
+		Message:  fmt.Sprintf("expected a literal, identifier, [ or { in pattern,but got:%s instead", p._curToken.Type),
+	})
+	return nil
+}
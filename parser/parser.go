@@ -5,6 +5,7 @@ import (
 	"interpreter/ast"
 	"interpreter/lexer"
 	"interpreter/token"
+	"math"
 	"strconv"
 )
 
@@ -12,12 +13,17 @@ const (
 	_ int = iota
 	LOWEST
 	ASSIGN
-	EQUALS     //==
-	LESSGRATER // > or <
-	SUM        // +
-	PRODUCT    // *
-	PREFIX     // ++
-	CALL       // myFunction(a,b)
+	TERNARY     // a ? b : c
+	COALESCE    // ??
+	LOGICAL_OR  // ||
+	LOGICAL_AND // &&
+	EQUALS      //==
+	LESSGRATER  // > or <
+	SUM         // + - | ^
+	PRODUCT     // * / % & << >>
+	PREFIX      // -x !x ~x
+	POWER       // ** 右结合，且比前缀运算符优先级高，-2 ** 2 == -(2 ** 2)
+	CALL        // myFunction(a,b)
 	INDEX
 )
 
@@ -35,6 +41,37 @@ var precedences = map[token.TokenType]int{
 	token.ASTERISK: PRODUCT,
 	token.LPAREN:   CALL,
 	token.LBRACKET: INDEX,
+	token.DOT:      INDEX,
+
+	token.QUESTION:          TERNARY,
+	token.NULLISH:           COALESCE,
+	token.OPTIONAL_DOT:      INDEX,
+	token.OPTIONAL_LBRACKET: INDEX,
+	token.INCREMENT:         CALL,
+	token.DECREMENT:         CALL,
+
+	//位运算的优先级与Go一致，高于比较运算，因此 x & 1 == 0 等价于 (x & 1) == 0
+	token.OR:          LOGICAL_OR,
+	token.AND:         LOGICAL_AND,
+	token.BIT_OR:      SUM,
+	token.BIT_XOR:     SUM,
+	token.PERCENT:     PRODUCT,
+	token.BIT_AND:     PRODUCT,
+	token.SHIFT_LEFT:  PRODUCT,
+	token.SHIFT_RIGHT: PRODUCT,
+	token.POWER:       POWER,
+
+	token.PLUS_ASSIGN:        ASSIGN,
+	token.MINUS_ASSIGN:       ASSIGN,
+	token.ASTERISK_ASSIGN:    ASSIGN,
+	token.SLASH_ASSIGN:       ASSIGN,
+	token.PERCENT_ASSIGN:     ASSIGN,
+	token.POWER_ASSIGN:       ASSIGN,
+	token.BIT_AND_ASSIGN:     ASSIGN,
+	token.BIT_OR_ASSIGN:      ASSIGN,
+	token.BIT_XOR_ASSIGN:     ASSIGN,
+	token.SHIFT_LEFT_ASSIGN:  ASSIGN,
+	token.SHIFT_RIGHT_ASSIGN: ASSIGN,
 }
 
 // 语法分析器
@@ -42,9 +79,18 @@ type Parser struct {
 	_lexer          *lexer.Lexer
 	_curToken       token.Token
 	_peekToken      token.Token
-	_errors         []string
+	_errors         []*ParseError
+	_panicking      bool //当前语句已出错，见reportError
+	_loopDepth      int  //当前所在的循环层数，用于检查break和continue的位置
+	_braceDepth     int  //当前token之前尚未闭合的 { 的数量，用于出错后的同步，见synchronize
+	_parenDepth     int  //当前token之前尚未闭合的 ( 和 [ 的数量
+	_guardDepth     int  //正在解析的match guard所在的括号层数，不在guard中时为-1，见arrowAllowed
 	_prefixParseFns map[token.TokenType]prefixParseFn
 	_infixParseFns  map[token.TokenType]infixParseFn
+	_scopes         []map[string]bool //各层作用域中声明的名字及其是否为常量，见scope.go
+	_groupElement   token.Position    //正在解析的括号表达式中当前元素的起始位置，见parseGroupedExpression
+	_blockParen     int               //当前语句块开始时尚未闭合的 ( 和 [ 的数量，见inParens
+	_blockEnd       token.Position    //最近解析完的语句块或match结尾的 } 的位置，见atStatementEnd
 }
 
 // 向前缀函数和中缀函数map中注册方法
@@ -55,25 +101,44 @@ func (p *Parser) registerInfixParseFn(tpe token.TokenType, fn infixParseFn) {
 	p._infixParseFns[tpe] = fn
 }
 func (p *Parser) nextToken() {
+	switch p._curToken.Type {
+	case token.LBRACE:
+		p._braceDepth++
+	case token.RBRACE:
+		p._braceDepth--
+	case token.LPAREN, token.LBRACKET, token.OPTIONAL_LBRACKET:
+		p._parenDepth++
+	case token.RPAREN, token.RBRACKET:
+		p._parenDepth--
+	}
 	p._curToken = p._peekToken
 	p._peekToken = p._lexer.NextToken()
 }
 func New(lexer *lexer.Lexer) *Parser {
-	p := &Parser{_lexer: lexer}
+	p := &Parser{_lexer: lexer, _guardDepth: -1}
+	p.pushScope()
 
 	p._prefixParseFns = make(map[token.TokenType]prefixParseFn)
 	p.registerPrefixParseFn(token.IDENT, p.parseIdentifier)
 	p.registerPrefixParseFn(token.INT, p.parseIntergerLiberal)
+	p.registerPrefixParseFn(token.FLOAT, p.parseFloatLiteral)
 	p.registerPrefixParseFn(token.MINUS, p.parsePrefixExpression)
 	p.registerPrefixParseFn(token.BANG, p.parsePrefixExpression)
+	p.registerPrefixParseFn(token.TILDE, p.parsePrefixExpression)
+	p.registerPrefixParseFn(token.INCREMENT, p.parsePrefixIncrement)
+	p.registerPrefixParseFn(token.DECREMENT, p.parsePrefixIncrement)
 	p.registerPrefixParseFn(token.TRUE, p.parseBoolean)
 	p.registerPrefixParseFn(token.FALSE, p.parseBoolean)
+	p.registerPrefixParseFn(token.NULL, p.parseNullLiteral)
 	p.registerPrefixParseFn(token.LPAREN, p.parseGroupedExpression)
 	p.registerPrefixParseFn(token.IF, p.parseIfExpression)
+	p.registerPrefixParseFn(token.MATCH, p.parseMatchExpression)
 	p.registerPrefixParseFn(token.FUNCTION, p.parseFunctionalLiteral)
 	p.registerPrefixParseFn(token.STRING, p.parseStringLiteral)
+	p.registerPrefixParseFn(token.TEMPLATE_HEAD, p.parseInterpolatedString)
 	p.registerPrefixParseFn(token.LBRACKET, p.parseArrayLiteral)
 	p.registerPrefixParseFn(token.LBRACE, p.parseHashingLiteral)
+	p.registerPrefixParseFn(token.ILLEGAL, p.parseIllegal)
 
 	p._infixParseFns = make(map[token.TokenType]infixParseFn)
 	p.registerInfixParseFn(token.PLUS, p.parseInfixExpression)
@@ -82,13 +147,34 @@ func New(lexer *lexer.Lexer) *Parser {
 	p.registerInfixParseFn(token.ASTERISK, p.parseInfixExpression)
 	p.registerInfixParseFn(token.EQ, p.parseInfixExpression)
 	p.registerInfixParseFn(token.NOT_EQ, p.parseInfixExpression)
-	p.registerInfixParseFn(token.LT, p.parseInfixExpression)
-	p.registerInfixParseFn(token.GT, p.parseInfixExpression)
-	p.registerInfixParseFn(token.LT_OR_EQ, p.parseInfixExpression)
-	p.registerInfixParseFn(token.GT_OR_EQ, p.parseInfixExpression)
+	p.registerInfixParseFn(token.LT, p.parseComparisonExpression)
+	p.registerInfixParseFn(token.GT, p.parseComparisonExpression)
+	p.registerInfixParseFn(token.LT_OR_EQ, p.parseComparisonExpression)
+	p.registerInfixParseFn(token.GT_OR_EQ, p.parseComparisonExpression)
 	p.registerInfixParseFn(token.LBRACKET, p.parseIndexExpression)
+	p.registerInfixParseFn(token.DOT, p.parsePropertyExpression)
+	p.registerInfixParseFn(token.NULLISH, p.parseInfixExpression)
+	p.registerInfixParseFn(token.QUESTION, p.parseConditionalExpression)
+	p.registerInfixParseFn(token.OPTIONAL_DOT, p.parseOptionalChain)
+	p.registerInfixParseFn(token.OPTIONAL_LBRACKET, p.parseOptionalIndexExpression)
+	p.registerInfixParseFn(token.INCREMENT, p.parsePostfixIncrement)
+	p.registerInfixParseFn(token.DECREMENT, p.parsePostfixIncrement)
 	p.registerInfixParseFn(token.ASSIGN, p.parseInfixExpression)
 	p.registerInfixParseFn(token.LPAREN, p.parseCallExpression)
+	p.registerInfixParseFn(token.PERCENT, p.parseInfixExpression)
+	p.registerInfixParseFn(token.POWER, p.parseInfixExpression)
+	p.registerInfixParseFn(token.AND, p.parseInfixExpression)
+	p.registerInfixParseFn(token.OR, p.parseInfixExpression)
+	p.registerInfixParseFn(token.BIT_AND, p.parseInfixExpression)
+	p.registerInfixParseFn(token.BIT_OR, p.parseInfixExpression)
+	p.registerInfixParseFn(token.BIT_XOR, p.parseInfixExpression)
+	p.registerInfixParseFn(token.SHIFT_LEFT, p.parseInfixExpression)
+	p.registerInfixParseFn(token.SHIFT_RIGHT, p.parseInfixExpression)
+	for tpe, precedence := range precedences {
+		if precedence == ASSIGN {
+			p.registerInfixParseFn(tpe, p.parseInfixExpression)
+		}
+	}
 
 	//滑动两次，以初始化curToken和peekToken
 	p.nextToken()
@@ -99,13 +185,22 @@ func New(lexer *lexer.Lexer) *Parser {
 func (p *Parser) parseBoolean() ast.Expression {
 	return &ast.Boolean{Token: p._curToken, Value: p.curTokenIs(token.TRUE)}
 }
+func (p *Parser) parseNullLiteral() ast.Expression {
+	return &ast.NullLiteral{Token: p._curToken}
+}
 func (p *Parser) ParseProgram() *ast.Program {
 	program := &ast.Program{}
 	program.Statements = []ast.Statement{}
 
 	for !p.curTokenIs(token.EOF) {
+		depth := p._braceDepth
 		stmt := p.parseStatement()
-		program.Statements = append(program.Statements, stmt)
+		//出错的语句被丢弃，跳到下一条语句继续解析，以便一次报告所有错误
+		if p._panicking {
+			p.synchronize(depth)
+		} else {
+			program.Statements = append(program.Statements, stmt)
+		}
 		p.nextToken()
 	}
 	return program
@@ -118,6 +213,14 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 	}
 
 	precedence := p.curPrecedence()
+	//括号中的 a = 1 可能是箭头函数参数的默认值，由parseGroupedExpression确定后再检查
+	if ident, ok := left.(*ast.Identifier); ok && precedence == ASSIGN && !(p.curTokenIs(token.ASSIGN) && p.isGroupDefault(ident)) {
+		p.checkAssign(ident)
+	}
+	//右结合的运算符，右侧以低一级的优先级解析，使 2 ** 3 ** 2 == 2 ** (3 ** 2)，a = b = 1 == a = (b = 1)
+	if p.curTokenIs(token.POWER) || precedence == ASSIGN {
+		precedence -= 1
+	}
 	p.nextToken()
 	expression.Right = p.parseExpression(precedence)
 
@@ -125,10 +228,16 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 }
 func (p *Parser) parseStatement() ast.Statement {
 	switch p._curToken.Type {
-	case token.LET:
+	case token.LET, token.CONST:
 		return p.parseLetStatement()
 	case token.RETURN:
 		return p.parseReturnStatement()
+	case token.WHILE:
+		return p.parseWhileStatement()
+	case token.FOR:
+		return p.parseForStatement()
+	case token.BREAK, token.CONTINUE:
+		return p.parseLoopControlStatement()
 	default:
 		return p.parseExpressionStatement()
 	}
@@ -146,39 +255,97 @@ Value :TODO
 func (p *Parser) parseLetStatement() *ast.LetStatement {
 	stmt := &ast.LetStatement{Token: p._curToken}
 
-	if !p.expectedPeek(token.IDENT) {
-		p.peekError(token.IDENT)
-		return nil
+	//let [a, b] = ... 和 let {a, b} = ... 为解构
+	if p.peekTokenIs(token.LBRACKET) || p.peekTokenIs(token.LBRACE) {
+		p.nextToken()
+		stmt.Pattern = p.parsePattern()
+		if stmt.Pattern == nil {
+			return nil
+		}
+	} else {
+		if !p.expectedPeek(token.IDENT) {
+			return nil
+		}
+		stmt.Name = &ast.Identifier{Token: p._curToken, Value: p._curToken.Literal}
 	}
-
-	stmt.Name = &ast.Identifier{Token: p._curToken, Value: p._curToken.Literal}
 	if !p.expectedPeek(token.ASSIGN) {
-		p.peekError(token.ASSIGN)
 		return nil
 	}
 	p.nextToken()
 	stmt.Value = p.parseExpression(LOWEST)
 
-	for !p.curTokenIs(token.SEMICOLON) {
-		p.nextToken()
+	//值中不能引用正在声明的名字，因此在解析完值之后再声明
+	if stmt.Pattern != nil {
+		p.declarePattern(stmt.Pattern, stmt.IsConst())
+	} else {
+		p.declare(stmt.Name, stmt.IsConst())
 	}
+	p.expectStatementEnd()
 	return stmt
 }
 
+// return之后可以没有返回值，此时返回null
 func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	stmt := &ast.ReturnStatement{Token: p._curToken}
 
-	p.nextToken()
-	stmt.ReturnValue = p.parseExpression(LOWEST)
-	for !p.peekTokenIs(token.SEMICOLON) {
+	if !p.atStatementEnd() {
 		p.nextToken()
+		stmt.ReturnValue = p.parseExpression(LOWEST)
 	}
-
-	p.nextToken()
+	p.expectStatementEnd()
 
 	return stmt
 }
 
+/*
+当前token是否在当前语句块内的 ( 或 [ 中，当前token本身是 ( 或 [ 时也算在内，是 ) 或 ] 时不算
+括号内的换行不会结束语句，例如 foo(a 换行后接 - 1) 时 - 仍然是减号
+*/
+func (p *Parser) inParens() bool {
+	depth := p._parenDepth
+	switch p._curToken.Type {
+	case token.LPAREN, token.LBRACKET, token.OPTIONAL_LBRACKET:
+		depth++
+	case token.RPAREN, token.RBRACKET:
+		depth--
+	}
+	return depth > p._blockParen
+}
+
+/*
+下一个token是否可以结束当前语句，即 ; } EOF 或者下一个token在新的一行
+以语句块的 } 结尾的语句本身已经结束，例如 if (x) { a } let y = 1 和 let f = fn() { 1 } f()
+哈希表字面量的 } 不算，{"a": 1} x 仍然是错误
+*/
+func (p *Parser) atStatementEnd() bool {
+	return p.peekTokenIs(token.SEMICOLON) || p.peekTokenIs(token.RBRACE) || p.peekTokenIs(token.EOF) || p._peekToken.NewlineBefore ||
+		(p.curTokenIs(token.RBRACE) && p._curToken.Pos == p._blockEnd)
+}
+
+/*
+检查语句是否结束，结尾的 ; 可以省略
+存在 ; 时读取它，使当前token停在语句的最后一个token上
+*/
+func (p *Parser) expectStatementEnd() {
+	//语句已经出错时不再读取 ; ，由synchronize跳过语句剩余的部分
+	if p._panicking {
+		return
+	}
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+		return
+	}
+	if p.atStatementEnd() {
+		return
+	}
+	p.reportError(&ParseError{
+		Pos:      p._peekToken.Pos,
+		Expected: token.SEMICOLON,
+		Actual:   p._peekToken,
+		Message:  fmt.Sprintf("expected ; or newline after statement,but got:%s instead", p._peekToken.Type),
+	})
+}
+
 /*
 parseExpression方法用于解析前缀与中缀表达式，只通过观察代码理解较为抽象，因此举例说明
 初始parseExpression传入的都是LOWEST
@@ -205,13 +372,24 @@ func (p *Parser) parseExpression(precedent int) ast.Expression {
 	prefix := p._prefixParseFns[p._curToken.Type]
 
 	if prefix == nil {
-		err := "noPrefixParseFnErr! for:" + p._curToken.Literal
-		p._errors = append(p._errors, err)
+		p.reportError(&ParseError{
+			Pos:      p._curToken.Pos,
+			Expected: "expression",
+			Actual:   p._curToken,
+			Message:  fmt.Sprintf("expected an expression,but got:%s instead", p._curToken.Type),
+		})
 		return nil
 	}
 	left := prefix()
 
-	for !p.peekTokenIs(token.SEMICOLON) && precedent < p.peekPrecedence() {
+	for !p._panicking && !p.peekTokenIs(token.SEMICOLON) && precedent < p.peekPrecedence() {
+		//行首的 ( [ ++ -- - + 开始一条新的语句，而不是调用、下标、后缀自增自减或加减运算
+		//因此 let x = 5 换行后的 -1 是单独的语句，表达式需要跨行时把运算符写在行尾，在括号内则不受限制
+		if p._peekToken.NewlineBefore && !p.inParens() && (p.peekTokenIs(token.LPAREN) || p.peekTokenIs(token.LBRACKET) ||
+			p.peekTokenIs(token.INCREMENT) || p.peekTokenIs(token.DECREMENT) ||
+			p.peekTokenIs(token.MINUS) || p.peekTokenIs(token.PLUS)) {
+			return left
+		}
 		infix := p._infixParseFns[p._peekToken.Type]
 		if infix == nil {
 			return left
@@ -229,23 +407,60 @@ func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
 	stmt := &ast.ExpressionStatement{Token: p._curToken}
 
 	stmt.Expression = p.parseExpression(LOWEST)
-	if p.peekTokenIs(token.SEMICOLON) {
-		p.nextToken()
-	}
+	p.expectStatementEnd()
 
 	return stmt
 }
+
+// 词法分析器产生的非法token，Literal中为非法字符或错误原因
+func (p *Parser) parseIllegal() ast.Expression {
+	p.addError(p._curToken, "illegal token: "+p._curToken.Literal)
+	return nil
+}
 func (p *Parser) parseIdentifier() ast.Expression {
-	return &ast.Identifier{Token: p._curToken, Value: p._curToken.Literal}
+	ident := &ast.Identifier{Token: p._curToken, Value: p._curToken.Literal}
+	//x => body
+	if p.peekTokenIs(token.ARROW) && p.arrowAllowed(p.depth()) {
+		p.nextToken()
+		return p.parseArrowFunction(ident.Token, []ast.Expression{ident})
+	}
+	return ident
 }
-func (p *Parser) parseIntergerLiberal() ast.Expression {
 
-	value, err := strconv.ParseInt(p._curToken.Literal, 0, 64)
+/*
+整数字面量只有 0x 0o 0b 三种前缀，以0开头的十进制数如 017 是错误，避免被当作八进制
+负号是单独的前缀运算符，字面量本身不能超过int64的最大值，因此最小值需要写成 -9223372036854775807 - 1
+*/
+func (p *Parser) parseIntergerLiberal() ast.Expression {
+	literal := p._curToken.Literal
+	if len(literal) > 1 && literal[0] == '0' && (isDigit(literal[1]) || literal[1] == '_') {
+		p.addError(p._curToken, fmt.Sprintf("invalid integer literal %s, leading zeros are not allowed, use 0o for octal", literal))
+		return &ast.IntegerLiteral{Token: p._curToken}
+	}
+	value, err := strconv.ParseInt(literal, 0, 64)
 	if err != nil {
-		p._errors = append(p._errors, err.Error())
+		if numErr, ok := err.(*strconv.NumError); ok && numErr.Err == strconv.ErrRange {
+			p.addError(p._curToken, fmt.Sprintf("integer literal %s is out of range, max is %d", p._curToken.Literal, int64(math.MaxInt64)))
+		} else {
+			p.addError(p._curToken, "invalid integer literal "+p._curToken.Literal)
+		}
 	}
 	return &ast.IntegerLiteral{Token: p._curToken, Value: value}
 }
+func isDigit(ch byte) bool {
+	return '0' <= ch && ch <= '9'
+}
+func (p *Parser) parseFloatLiteral() ast.Expression {
+	value, err := strconv.ParseFloat(p._curToken.Literal, 64)
+	if err != nil {
+		if numErr, ok := err.(*strconv.NumError); ok && numErr.Err == strconv.ErrRange {
+			p.addError(p._curToken, "float literal "+p._curToken.Literal+" out of range")
+		} else {
+			p.addError(p._curToken, "invalid float literal "+p._curToken.Literal)
+		}
+	}
+	return &ast.FloatLiteral{Token: p._curToken, Value: value}
+}
 func (p *Parser) parsePrefixExpression() ast.Expression {
 	expression := &ast.PrefixExpression{
 		Token:    p._curToken,
@@ -263,17 +478,23 @@ func (p *Parser) peekTokenIs(t token.TokenType) bool {
 	return p._peekToken.Type == t
 }
 
+// 下一个token为t时前进一步，否则记录错误
 func (p *Parser) expectedPeek(t token.TokenType) bool {
 	if p.peekTokenIs(t) {
 		p.nextToken()
 		return true
 	}
+	p.peekError(t)
 	return false
 }
 
 func (p *Parser) peekError(t token.TokenType) {
-	err := fmt.Sprintf("expected next token to be %s,but got:%s instead", t, p._peekToken.Type)
-	p._errors = append(p._errors, err)
+	p.reportError(&ParseError{
+		Pos:      p._peekToken.Pos,
+		Expected: string(t),
+		Actual:   p._peekToken,
+		Message:  fmt.Sprintf("expected next token to be %s,but got:%s instead", t, p._peekToken.Type),
+	})
 }
 
 type (
@@ -293,15 +514,68 @@ func (p *Parser) curPrecedence() int {
 	}
 	return LOWEST
 }
+
+/*
+( 开始的可能是分组表达式，也可能是箭头函数的参数列表 (a, b = 1, ...rest) => body
+先按逗号分隔的表达式解析，) 之后是 => 时再转换为参数
+*/
 func (p *Parser) parseGroupedExpression() ast.Expression {
+	start := p._curToken
+	depth := p.depth()
+	if p.peekTokenIs(token.RPAREN) {
+		p.nextToken()
+		if !p.expectedPeek(token.ARROW) {
+			return nil
+		}
+		return p.parseArrowFunction(start, nil)
+	}
 	p.nextToken()
 
-	exp := p.parseExpression(LOWEST)
-
+	defer func(outer token.Position) { p._groupElement = outer }(p._groupElement)
+	starts := []token.Position{p._curToken.Pos}
+	p._groupElement = p._curToken.Pos
+	elements := []ast.Expression{p.parseListElement()}
+	for p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+		p.nextToken()
+		starts = append(starts, p._curToken.Pos)
+		p._groupElement = p._curToken.Pos
+		elements = append(elements, p.parseListElement())
+	}
 	if !p.expectedPeek(token.RPAREN) {
 		return nil
 	}
-	return exp
+	//元素解析出错时已经报告了错误，不再当作参数列表解析，否则会访问为nil的元素
+	if p._panicking {
+		return nil
+	}
+	for _, element := range elements {
+		if element == nil {
+			return nil
+		}
+	}
+	if p.peekTokenIs(token.ARROW) && p.arrowAllowed(depth) {
+		p.nextToken()
+		return p.parseArrowFunction(start, elements)
+	}
+	//不是参数列表，补上推迟的常量赋值检查
+	for i, element := range elements {
+		if assign, ok := element.(*ast.InfixExpression); ok && assign.Operator == "=" {
+			if ident, ok := assign.Left.(*ast.Identifier); ok && ident.Token.Pos == starts[i] {
+				p.checkAssign(ident)
+			}
+		}
+	}
+	if _, ok := elements[0].(*ast.SpreadExpression); ok || len(elements) != 1 {
+		p.reportError(&ParseError{
+			Pos:      p._peekToken.Pos,
+			Expected: token.ARROW,
+			Actual:   p._peekToken,
+			Message:  fmt.Sprintf("expected => after parameter list,but got:%s instead", p._peekToken.Type),
+		})
+		return nil
+	}
+	return elements[0]
 }
 
 func (p *Parser) parseIfExpression() ast.Expression {
@@ -326,6 +600,19 @@ func (p *Parser) parseIfExpression() ast.Expression {
 	if p.peekTokenIs(token.ELSE) {
 		p.nextToken()
 
+		//else if 转换为只包含一个if表达式的else块，即 else { if (...) {...} }
+		if p.peekTokenIs(token.IF) {
+			p.nextToken()
+			block := &ast.BlockStatement{Token: p._curToken}
+			nested := p.parseIfExpression()
+			if nested == nil {
+				return nil
+			}
+			block.Statements = []ast.Statement{&ast.ExpressionStatement{Token: block.Token, Expression: nested}}
+			expression.Alternative = block
+			return expression
+		}
+
 		if !p.expectedPeek(token.LBRACE) {
 			return nil
 		}
@@ -338,41 +625,78 @@ func (p *Parser) parseIfExpression() ast.Expression {
 func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 	block := &ast.BlockStatement{Token: p._curToken}
 	block.Statements = []ast.Statement{}
+	//语句块中的语句不在括号内，例如 f(fn() { ... }) 的函数体中换行照常结束语句
+	defer func(outer int) { p._blockParen = outer }(p._blockParen)
+	p._blockParen = p._parenDepth
 
 	p.nextToken()
 
 	for !p.curTokenIs(token.RBRACE) && !p.curTokenIs(token.EOF) {
+		depth := p._braceDepth
 		stmt := p.parseStatement()
-		if stmt != nil {
+		if p._panicking {
+			p.synchronize(depth)
+			//出错的语句已经读到了块的结尾
+			if p.curTokenIs(token.RBRACE) {
+				break
+			}
+		} else if stmt != nil {
 			block.Statements = append(block.Statements, stmt)
 		}
 		p.nextToken()
 	}
+	if !p.curTokenIs(token.RBRACE) {
+		p.addError(p._curToken, "expected } to close block,but got:EOF instead")
+		return block
+	}
+	block.RBrace = p._curToken
+	p._blockEnd = p._curToken.Pos
 	return block
 }
 
-func (p *Parser) parseFunctionParameters() []*ast.Identifier {
-	identifiers := []*ast.Identifier{}
+/*
+解析参数列表 (a, b = 10, ...rest)
+有默认值的参数之后不能再出现没有默认值的参数，...rest 只能是最后一个参数
+*/
+func (p *Parser) parseFunctionParameters(lit *ast.FunctionLiteral) bool {
+	lit.Parameters = []*ast.Identifier{}
+	lit.Defaults = []ast.Expression{}
 
 	if p.peekTokenIs(token.RPAREN) {
 		p.nextToken()
-		return identifiers
+		return true
 	}
-	p.nextToken()
-
-	ident := &ast.Identifier{Token: p._curToken, Value: p._curToken.Literal}
-	identifiers = append(identifiers, ident)
+	for {
+		if p.peekTokenIs(token.ELLIPSIS) {
+			p.nextToken()
+			if !p.expectedPeek(token.IDENT) {
+				return false
+			}
+			lit.Rest = &ast.Identifier{Token: p._curToken, Value: p._curToken.Literal}
+			break
+		}
+		if !p.expectedPeek(token.IDENT) {
+			return false
+		}
+		ident := &ast.Identifier{Token: p._curToken, Value: p._curToken.Literal}
+		var value ast.Expression
+		if p.peekTokenIs(token.ASSIGN) {
+			p.nextToken()
+			p.nextToken()
+			value = p.parseExpression(ASSIGN)
+		} else if len(lit.Defaults) > 0 && lit.Defaults[len(lit.Defaults)-1] != nil {
+			p.addError(ident.Token, fmt.Sprintf("parameter %s without default value follows parameter with default value", ident.Value))
+			return false
+		}
+		lit.Parameters = append(lit.Parameters, ident)
+		lit.Defaults = append(lit.Defaults, value)
 
-	for p.peekTokenIs(token.COMMA) {
-		p.nextToken()
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
 		p.nextToken()
-		ident := &ast.Identifier{Token: p._curToken, Value: p._curToken.Literal}
-		identifiers = append(identifiers, ident)
 	}
-	if !p.expectedPeek(token.RPAREN) {
-		return nil
-	}
-	return identifiers
+	return p.expectedPeek(token.RPAREN)
 }
 func (p *Parser) parseFunctionalLiteral() ast.Expression {
 	lit := &ast.FunctionLiteral{Token: p._curToken}
@@ -381,24 +705,74 @@ func (p *Parser) parseFunctionalLiteral() ast.Expression {
 		return nil
 	}
 
-	lit.Parameters = p.parseFunctionParameters()
+	if !p.parseFunctionParameters(lit) {
+		return nil
+	}
 
 	if !p.expectedPeek(token.LBRACE) {
 		return nil
 	}
 
+	//函数体内的break和continue不能跳出函数外的循环
+	loopDepth := p._loopDepth
+	p._loopDepth = 0
+	p.pushScope()
+	p.declareParameters(lit)
 	lit.Body = p.parseBlockStatement()
+	p.popScope()
+	p._loopDepth = loopDepth
 
 	return lit
 }
 
 func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
 	exp := &ast.CallExpression{Token: p._curToken, Function: function}
-	exp.Arguments = p.parseExpressionList(token.RPAREN)
+	if !p.parseCallArguments(exp) {
+		return nil
+	}
+	exp.RParen = p._curToken
 
 	return exp
 }
 
+/*
+解析调用参数，name: value 为关键字参数
+关键字参数必须位于所有位置参数之后，同一个名字不能出现两次
+*/
+func (p *Parser) parseCallArguments(exp *ast.CallExpression) bool {
+	exp.Arguments = []ast.Expression{}
+	if p.peekTokenIs(token.RPAREN) {
+		p.nextToken()
+		return true
+	}
+	names := map[string]bool{}
+	for {
+		p.nextToken()
+		if p.curTokenIs(token.IDENT) && p.peekTokenIs(token.COLON) {
+			name := &ast.Identifier{Token: p._curToken, Value: p._curToken.Literal}
+			if names[name.Value] {
+				p.addError(name.Token, fmt.Sprintf("duplicate keyword argument: %s", name.Value))
+				return false
+			}
+			names[name.Value] = true
+			p.nextToken()
+			p.nextToken()
+			exp.Keywords = append(exp.Keywords, &ast.KeywordArgument{Name: name, Value: p.parseExpression(LOWEST)})
+		} else {
+			if len(exp.Keywords) > 0 {
+				p.addError(p._curToken, "positional argument follows keyword argument")
+				return false
+			}
+			exp.Arguments = append(exp.Arguments, p.parseListElement())
+		}
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+	return p.expectedPeek(token.RPAREN)
+}
+
 /*
 之前用来获取函数调用时的参数，目前被parseExpressionList方法取代
 */
@@ -424,77 +798,187 @@ func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
 //		}
 //		return args
 //	}
-func (p *Parser) Errors() []string {
-	return p._errors
-}
 func (p *Parser) parseStringLiteral() ast.Expression {
 	return &ast.StringLiteral{Token: p._curToken, Value: p._curToken.Literal}
 
 }
 
+// "a${x}b" 的token为 TEMPLATE_HEAD IDENT TEMPLATE_TAIL，字符串片段和表达式交替出现
+func (p *Parser) parseInterpolatedString() ast.Expression {
+	str := &ast.InterpolatedString{Token: p._curToken}
+	str.Parts = append(str.Parts, &ast.StringLiteral{Token: p._curToken, Value: p._curToken.Literal})
+
+	for !p.curTokenIs(token.TEMPLATE_TAIL) {
+		p.nextToken()
+		if p.curTokenIs(token.TEMPLATE_MIDDLE) || p.curTokenIs(token.TEMPLATE_TAIL) {
+			p.addError(p._curToken, "empty expression in string interpolation")
+			return nil
+		}
+		str.Parts = append(str.Parts, p.parseExpression(LOWEST))
+
+		if !p.peekTokenIs(token.TEMPLATE_MIDDLE) && !p.peekTokenIs(token.TEMPLATE_TAIL) {
+			p.addError(p._peekToken, fmt.Sprintf("expected } to close string interpolation,but got:%s instead", p._peekToken.Type))
+			return nil
+		}
+		p.nextToken()
+		str.Parts = append(str.Parts, &ast.StringLiteral{Token: p._curToken, Value: p._curToken.Literal})
+	}
+	return str
+}
 func (p *Parser) parseArrayLiteral() ast.Expression {
 	array := &ast.ArrayLiteral{Token: p._curToken}
 
 	array.Elements = p.parseExpressionList(token.RBRACKET)
+	if p.curTokenIs(token.RBRACKET) {
+		array.RBracket = p._curToken
+	}
 	return array
 }
 func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
 	var elements = []ast.Expression{}
 
-	if p.expectedPeek(end) {
+	if p.peekTokenIs(end) {
+		p.nextToken()
 		return elements
 	}
 	p.nextToken()
-	elements = append(elements, p.parseExpression(LOWEST))
+	elements = append(elements, p.parseListElement())
 	for p.peekTokenIs(token.COMMA) {
 		p.nextToken()
 		p.nextToken()
-		elements = append(elements, p.parseExpression(LOWEST))
+		elements = append(elements, p.parseListElement())
 	}
 	if !p.expectedPeek(end) {
 		return nil
 	}
 	return elements
 }
+
+// 调用参数、数组和哈希表中的元素，可以是 ...value
+func (p *Parser) parseListElement() ast.Expression {
+	if p.curTokenIs(token.ELLIPSIS) {
+		spread := &ast.SpreadExpression{Token: p._curToken}
+		p.nextToken()
+		spread.Value = p.parseExpression(LOWEST)
+		return spread
+	}
+	return p.parseExpression(LOWEST)
+}
+
+// a[index] ，出现 : 时为切片 a[start:stop:step]
 func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
-	exp := &ast.IndexExpression{Token: p._curToken, Left: left}
-	p.nextToken()
-	exp.Index = p.parseExpression(LOWEST)
+	tok := p._curToken
+	var index ast.Expression
+	if !p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		index = p.parseExpression(LOWEST)
+	}
+	if p.peekTokenIs(token.COLON) {
+		return p.parseSliceExpression(tok, left, index)
+	}
 
+	exp := &ast.IndexExpression{Token: tok, Left: left, Index: index}
 	if !p.expectedPeek(token.RBRACKET) {
 		return nil
 	}
+	exp.RBracket = p._curToken
 
 	return exp
 }
-func (p *Parser) parseHashingLiteral() ast.Expression {
-	hl := &ast.HashLiteral{Token: p._curToken, Pairs: make(map[ast.Expression]ast.Expression)}
-	if p.expectedPeek(token.RBRACE) {
-		return hl
-	}
+
+// 进入时下一个token为start之后的 :
+func (p *Parser) parseSliceExpression(tok token.Token, left, start ast.Expression) ast.Expression {
+	exp := &ast.SliceExpression{Token: tok, Left: left, Start: start}
 	p.nextToken()
-	key := p.parseExpression(LOWEST)
-	if !p.expectedPeek(token.COLON) {
+	if !p.peekTokenIs(token.COLON) && !p.peekTokenIs(token.RBRACKET) {
+		p.nextToken()
+		exp.Stop = p.parseExpression(LOWEST)
+	}
+	if p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		if !p.peekTokenIs(token.RBRACKET) {
+			p.nextToken()
+			exp.Step = p.parseExpression(LOWEST)
+		}
+	}
+	if !p.expectedPeek(token.RBRACKET) {
 		return nil
 	}
-	p.nextToken()
+	exp.RBracket = p._curToken
 
-	value := p.parseExpression(LOWEST)
+	return exp
+}
 
-	hl.Pairs[key] = value
-	for p.expectedPeek(token.COMMA) {
+// obj.name ，name只能是标识符
+func (p *Parser) parsePropertyExpression(left ast.Expression) ast.Expression {
+	exp := &ast.PropertyExpression{Token: p._curToken, Object: left}
+	if !p.expectedPeek(token.IDENT) {
+		return nil
+	}
+	exp.Property = &ast.Identifier{Token: p._curToken, Value: p._curToken.Literal}
+
+	return exp
+}
+
+// obj?.name 或 f?.(args)
+func (p *Parser) parseOptionalChain(left ast.Expression) ast.Expression {
+	if p.peekTokenIs(token.LPAREN) {
 		p.nextToken()
-		key := p.parseExpression(LOWEST)
-		if !p.expectedPeek(token.COLON) {
+		exp, ok := p.parseCallExpression(left).(*ast.CallExpression)
+		if !ok {
 			return nil
 		}
+		exp.Optional = true
+		return exp
+	}
+	exp := &ast.PropertyExpression{Token: p._curToken, Object: left, Optional: true}
+	if !p.expectedPeek(token.IDENT) {
+		return nil
+	}
+	exp.Property = &ast.Identifier{Token: p._curToken, Value: p._curToken.Literal}
+
+	return exp
+}
+
+// a?[index] 或 a?[start:stop:step]
+func (p *Parser) parseOptionalIndexExpression(left ast.Expression) ast.Expression {
+	switch exp := p.parseIndexExpression(left).(type) {
+	case *ast.IndexExpression:
+		exp.Optional = true
+		return exp
+	case *ast.SliceExpression:
+		exp.Optional = true
+		return exp
+	}
+	return nil
+}
+func (p *Parser) parseHashingLiteral() ast.Expression {
+	hl := &ast.HashLiteral{Token: p._curToken, Pairs: make(map[ast.Expression]ast.Expression)}
+	if p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+		hl.RBrace = p._curToken
+		return hl
+	}
+	for {
+		p.nextToken()
+		key := p.parseListElement()
+		hl.Order = append(hl.Order, key)
+		if _, ok := key.(*ast.SpreadExpression); !ok {
+			if !p.expectedPeek(token.COLON) {
+				return nil
+			}
+			p.nextToken()
+			hl.Pairs[key] = p.parseExpression(LOWEST)
+		}
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
 		p.nextToken()
-		value := p.parseExpression(LOWEST)
-		hl.Pairs[key] = value
 	}
 
 	if !p.expectedPeek(token.RBRACE) {
 		return nil
 	}
+	hl.RBrace = p._curToken
 	return hl
 }
@@ -390,6 +390,66 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"add(a * b[2], b[1], 2 * [1, 2][1])",
 			"add((a * (b[2])), (b[1]), (2 * ([1, 2][1])))",
 		},
+		{
+			"a || b && c",
+			"(a || (b && c))",
+		},
+		{
+			"a == 1 && b < 2 || !c",
+			"(((a == 1) && (b < 2)) || (!c))",
+		},
+		{
+			"a + b % c",
+			"(a + (b % c))",
+		},
+		{
+			"2 ** 3 ** 2",
+			"(2 ** (3 ** 2))",
+		},
+		{
+			"-2 ** 2",
+			"(-(2 ** 2))",
+		},
+		{
+			"a * b ** c",
+			"(a * (b ** c))",
+		},
+		{
+			"x & 1 == 0",
+			"((x & 1) == 0)",
+		},
+		{
+			"a | b ^ c & d << 1",
+			"((a | b) ^ ((c & d) << 1))",
+		},
+		{
+			"~a + 1",
+			"((~a) + 1)",
+		},
+		{
+			"a = b = 1 + 2",
+			"(a = (b = (1 + 2)))",
+		},
+		{
+			"a += b * 2",
+			"(a += (b * 2))",
+		},
+		{
+			"a[i] <<= 1",
+			"((a[i]) <<= 1)",
+		},
+		{
+			"-a.b * c.d(1)",
+			"((-(a.b)) * (c.d)(1))",
+		},
+		{
+			"a.b.c[0].d",
+			"((((a.b).c)[0]).d)",
+		},
+		{
+			"\"abc\".upper().len()",
+			"((abc.upper)().len)()",
+		},
 	}
 
 	for _, tt := range tests {
@@ -757,3 +817,1057 @@ func testBoolLiteral(t *testing.T, exp ast.Expression, value bool) bool {
 
 	return true
 }
+func TestNodePositions(t *testing.T) {
+	input := "let a = add(1, 2);\nif (a) { [1][0] }"
+	lexer := lexer.New(input)
+	parser := New(lexer)
+	program := parser.ParseProgram()
+	chenckParserErrors(t, parser)
+
+	tests := []struct {
+		node     ast.Node
+		expected string
+	}{
+		{program.Statements[0], "1:1-1:18"},
+		{program.Statements[0].(*ast.LetStatement).Value, "1:9-1:18"},
+		{program.Statements[1], "2:1-2:18"},
+		{program.Statements[1].(*ast.ExpressionStatement).Expression.(*ast.IfExpression).Consequence.Statements[0], "2:10-2:16"},
+		{program, "1:1-2:18"},
+	}
+	for _, tt := range tests {
+		actual := tt.node.Pos().String() + "-" + tt.node.End().String()
+		if actual != tt.expected {
+			t.Errorf("position of %q wrong. expected=%s,got=%s", tt.node.String(), tt.expected, actual)
+		}
+	}
+}
+func TestErrorPositions(t *testing.T) {
+	input := "let x = 5;\nlet = 10;"
+	lexer := lexer.New(input)
+	parser := New(lexer)
+	parser.ParseProgram()
+
+	errors := parser.Errors()
+	if len(errors) == 0 {
+		t.Fatalf("expected parser errors")
+	}
+	expected := "2:5: expected next token to be IDENT,but got:= instead"
+	if errors[0] != expected {
+		t.Errorf("wrong error. expected=%q,got=%q", expected, errors[0])
+	}
+}
+func TestIllegalTokenError(t *testing.T) {
+	input := "let x = 5;\nx + /* oops"
+	lexer := lexer.New(input)
+	parser := New(lexer)
+	parser.ParseProgram()
+
+	errors := parser.Errors()
+	expected := "2:5: illegal token: unterminated block comment"
+	if len(errors) == 0 || errors[0] != expected {
+		t.Errorf("wrong errors. expected first=%q,got=%q", expected, errors)
+	}
+}
+func TestFloatLiteralExpression(t *testing.T) {
+	input := `1.5 + 2e-3;`
+	lexer := lexer.New(input)
+	parser := New(lexer)
+	program := parser.ParseProgram()
+	chenckParserErrors(t, parser)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	infix, ok := stmt.Expression.(*ast.InfixExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not *ast.InfixExpression,got =%T", stmt.Expression)
+	}
+	for i, expected := range []float64{1.5, 0.002} {
+		operand := []ast.Expression{infix.Left, infix.Right}[i]
+		fl, ok := operand.(*ast.FloatLiteral)
+		if !ok {
+			t.Fatalf("operand is not *ast.FloatLiteral,got =%T", operand)
+		}
+		if fl.Value != expected {
+			t.Errorf("fl.Value is not %g,got=%g", expected, fl.Value)
+		}
+	}
+}
+func TestIntegerLiteralBases(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"0xFF", 255},
+		{"0o17", 15},
+		{"0b1010", 10},
+		{"1_000_000", 1000000},
+		{"0x_dead_BEEF", 0xdeadbeef},
+		{"9223372036854775807", 9223372036854775807},
+		{"0", 0},
+		{"10", 10},
+		{"0o017", 15},
+	}
+	for _, tt := range tests {
+		parser := New(lexer.New(tt.input))
+		program := parser.ParseProgram()
+		chenckParserErrors(t, parser)
+		testIntergerLiteral(t, program.Statements[0].(*ast.ExpressionStatement).Expression, tt.expected)
+	}
+}
+func TestIntegerLiteralErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let a = 1;\nlet b = 9223372036854775808;", "2:9: integer literal 9223372036854775808 is out of range, max is 9223372036854775807"},
+		{"0b12;", "1:1: invalid integer literal 0b12"},
+		{"1__0;", "1:1: invalid integer literal 1__0"},
+		{"x + 0x;", "1:5: invalid integer literal 0x"},
+		{"017", "1:1: invalid integer literal 017, leading zeros are not allowed, use 0o for octal"},
+		{"08", "1:1: invalid integer literal 08, leading zeros are not allowed, use 0o for octal"},
+		{"-9223372036854775808", "1:2: integer literal 9223372036854775808 is out of range, max is 9223372036854775807"},
+	}
+	for _, tt := range tests {
+		parser := New(lexer.New(tt.input))
+		parser.ParseProgram()
+		errors := parser.Errors()
+		if len(errors) != 1 || errors[0] != tt.expected {
+			t.Errorf("wrong errors. expected=%q,got=%q", tt.expected, errors)
+		}
+	}
+}
+func TestInterpolatedString(t *testing.T) {
+	input := `"Hello ${first + " " + last}!"`
+	lexer := lexer.New(input)
+	parser := New(lexer)
+	program := parser.ParseProgram()
+	chenckParserErrors(t, parser)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	str, ok := stmt.Expression.(*ast.InterpolatedString)
+	if !ok {
+		t.Fatalf("stmt.Expression is not *ast.InterpolatedString,got =%T", stmt.Expression)
+	}
+	if len(str.Parts) != 3 {
+		t.Fatalf("len(str.Parts) != 3,got=%d", len(str.Parts))
+	}
+	expected := `"Hello ${((first +  ) + last)}!"`
+	if str.String() != expected {
+		t.Errorf("str.String() wrong. expected=%q,got=%q", expected, str.String())
+	}
+	if str.End().Column != 31 {
+		t.Errorf("str.End() wrong. got=%s", str.End())
+	}
+}
+func TestInterpolatedStringErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"a ${} b"`, "1:6: empty expression in string interpolation"},
+		{`"a ${x y} b"`, "1:8: expected } to close string interpolation,but got:IDENT instead"},
+	}
+	for _, tt := range tests {
+		parser := New(lexer.New(tt.input))
+		parser.ParseProgram()
+		errors := parser.Errors()
+		if len(errors) == 0 || errors[0] != tt.expected {
+			t.Errorf("wrong errors. expected first=%q,got=%q", tt.expected, errors)
+		}
+	}
+}
+func TestParseErrorRecovery(t *testing.T) {
+	input := `let = 5;
+let x = 10;
+let y 3;
+let f = fn(a) {
+	let = 1;
+	a + ;
+	return a;
+};
+add(1, 2;
+let z = 1;
+`
+	parser := New(lexer.New(input))
+	program := parser.ParseProgram()
+
+	expected := []string{
+		"1:5: expected next token to be IDENT,but got:= instead",
+		"3:7: expected next token to be =,but got:INT instead",
+		"5:6: expected next token to be IDENT,but got:= instead",
+		"6:6: expected an expression,but got:; instead",
+		"9:9: expected next token to be ),but got:; instead",
+	}
+	errors := parser.Errors()
+	if len(errors) != len(expected) {
+		t.Fatalf("wrong number of errors. expected=%d,got=%d: %q", len(expected), len(errors), errors)
+	}
+	for i, msg := range expected {
+		if errors[i] != msg {
+			t.Errorf("errors[%d] wrong. expected=%q,got=%q", i, msg, errors[i])
+		}
+	}
+
+	//出错的语句被丢弃，其余语句正常解析
+	if len(program.Statements) != 3 {
+		t.Fatalf("len(program.Statements) != 3,got=%d", len(program.Statements))
+	}
+	testLetStatement(t, program.Statements[0], "x", "10")
+	fn := program.Statements[1].(*ast.LetStatement).Value.(*ast.FunctionLiteral)
+	if len(fn.Body.Statements) != 1 || fn.Body.Statements[0].String() != "return=a;" {
+		t.Errorf("function body wrong. got=%q", fn.Body.String())
+	}
+	testLetStatement(t, program.Statements[2], "z", "1")
+
+	//match分支出错后整个match被跳过，之后语句中的错误照常报告
+	parser = New(lexer.New("match (1) { 1 => }\nlet z = [1, 2;\nputs(1)"))
+	program = parser.ParseProgram()
+	expected = []string{
+		"1:18: expected an expression,but got:} instead",
+		"2:14: expected next token to be ],but got:; instead",
+	}
+	errors = parser.Errors()
+	if len(errors) != len(expected) {
+		t.Fatalf("wrong number of errors. expected=%d,got=%d: %q", len(expected), len(errors), errors)
+	}
+	for i, msg := range expected {
+		if errors[i] != msg {
+			t.Errorf("errors[%d] wrong. expected=%q,got=%q", i, msg, errors[i])
+		}
+	}
+	if len(program.Statements) != 1 || program.Statements[0].String() != "puts(1)" {
+		t.Errorf("statements wrong. got=%q", program.String())
+	}
+	//语句块中出错时，块后的 ; 交给synchronize跳过，后面语句中的错误照常报告
+	parser = New(lexer.New("let f = fn(x) { x + };\nlet a = ;\nlet b = ;\nlet c = 1"))
+	program = parser.ParseProgram()
+	expected = []string{
+		"1:21: expected an expression,but got:} instead",
+		"2:9: expected an expression,but got:; instead",
+		"3:9: expected an expression,but got:; instead",
+	}
+	errors = parser.Errors()
+	if len(errors) != len(expected) {
+		t.Fatalf("wrong number of errors. expected=%d,got=%d: %q", len(expected), len(errors), errors)
+	}
+	for i, msg := range expected {
+		if errors[i] != msg {
+			t.Errorf("errors[%d] wrong. expected=%q,got=%q", i, msg, errors[i])
+		}
+	}
+	if program.String() != "let f = fn(x);let c = 1;" {
+		t.Errorf("statements wrong. got=%q", program.String())
+	}
+}
+func TestParseErrorFields(t *testing.T) {
+	parser := New(lexer.New("let x = (1 + 2;"))
+	parser.ParseProgram()
+
+	errors := parser.ParseErrors()
+	if len(errors) != 1 {
+		t.Fatalf("len(errors) != 1,got=%d", len(errors))
+	}
+	err := errors[0]
+	if err.Expected != ")" || err.Actual.Type != ";" || err.Pos.Line != 1 || err.Pos.Column != 15 {
+		t.Errorf("wrong error fields. got=%+v", err)
+	}
+	if err.Error() != "1:15: expected next token to be ),but got:; instead" {
+		t.Errorf("wrong error message. got=%q", err.Error())
+	}
+}
+func TestUnclosedBlockError(t *testing.T) {
+	parser := New(lexer.New("if (x) { 1;"))
+	parser.ParseProgram()
+
+	errors := parser.Errors()
+	expected := "1:12: expected } to close block,but got:EOF instead"
+	if len(errors) != 1 || errors[0] != expected {
+		t.Errorf("wrong errors. expected=%q,got=%q", expected, errors)
+	}
+}
+func TestOptionalSemicolons(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let x = 5\nlet y = x + 1\ny", "let x = 5;let y = (x + 1);y"},
+		{"let x = 5", "let x = 5;"},
+		{"fn(){ return }", "fn()return=;"},
+		{"fn(){ return\n1 }", "fn()return=;1"},
+		{"let a = b\n(c)", "let a = b;c"},
+		{"a\n[1]", "a[1]"},
+		{"add(1,\n2)", "add(1, 2)"},
+		{"1 +\n2; 3", "(1 + 2)3"},
+		{"let x = 5\n-1\nputs(x)", "let x = 5;(-1)puts(x)"},
+		{"x\n- 1", "x(-1)"},
+		{"x -\n1", "(x - 1)"},
+		{"x\n!y", "x(!y)"},
+		{"x\n~y", "x(~y)"},
+		{"let x = (1\n + 2)", "let x = (1 + 2);"},
+		{"foo(a\n - 1)", "foo((a - 1))"},
+		{"foo(a,\n b\n (c))", "foo(a, b(c))"},
+		{"[a\n- 1, b\n+ c]", "[(a - 1), (b + c)]"},
+		{"(a)\n-1", "a(-1)"},
+		{"f(fn() { x\n-1 })", "f(fn()x(-1))"},
+		{"if (true) { 1 } let x = 2", "iftrue 1let x = 2;"},
+		{"let f = fn() { 1 } f()", "let f = fn()1;f()"},
+		{"while (c) { x } let x = 1", "whilec xlet x = 1;"},
+		{"for (x in xs) { x } f()", "for(x in xs) xf()"},
+		{"let v = match (x) { _ => 1 } v", "let v = matchx {_ => 1};v"},
+		{"let f = x => { x } f(1)", "let f = fn(x)x;f(1)"},
+		{"if (x) { a } else { b }", "ifx aelse b"},
+	}
+
+	for _, tt := range tests {
+		parser := New(lexer.New(tt.input))
+		program := parser.ParseProgram()
+		chenckParserErrors(t, parser)
+		if program.String() != tt.expected {
+			t.Errorf("input %q: expected=%q,got=%q", tt.input, tt.expected, program.String())
+		}
+	}
+}
+func TestRecoveryInsideParens(t *testing.T) {
+	parser := New(lexer.New("let a = foo(1 + ,\n 2)\nlet b = 1"))
+	program := parser.ParseProgram()
+	errors := parser.Errors()
+	if len(errors) != 1 || errors[0] != "1:17: expected an expression,but got:, instead" {
+		t.Errorf("wrong errors. got=%q", errors)
+	}
+	if program.String() != "let b = 1;" {
+		t.Errorf("statements wrong. got=%q", program.String())
+	}
+}
+func TestMissingStatementEnd(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let x = 5 let y = 6", "1:11: expected ; or newline after statement,but got:LET instead"},
+		{"x y\nlet z = 1", "1:3: expected ; or newline after statement,but got:IDENT instead"},
+		{"return 1 2", "1:10: expected ; or newline after statement,but got:INT instead"},
+		{`let h = {"a": 1} h`, "1:18: expected ; or newline after statement,but got:IDENT instead"},
+	}
+
+	for _, tt := range tests {
+		parser := New(lexer.New(tt.input))
+		parser.ParseProgram()
+		errors := parser.Errors()
+		if len(errors) != 1 || errors[0] != tt.expected {
+			t.Errorf("input %q: expected=%q,got=%q", tt.input, tt.expected, errors)
+		}
+	}
+}
+func TestLoopStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"while (x < 10) { x += 1 }", "while(x < 10) (x += 1)"},
+		{"for (x in arr) { puts(x) }", "for(x in arr) puts(x)"},
+		{"for (k, v in h) { break; }", "for(k, v in h) break;"},
+		{"while (true) { if (x) { continue } }", "whiletrue ifx continue;"},
+		{"while (a) {}\nb", "whilea b"},
+	}
+
+	for _, tt := range tests {
+		parser := New(lexer.New(tt.input))
+		program := parser.ParseProgram()
+		chenckParserErrors(t, parser)
+		if program.String() != tt.expected {
+			t.Errorf("input %q: expected=%q,got=%q", tt.input, tt.expected, program.String())
+		}
+	}
+
+	program := New(lexer.New("for (i, x in [1, 2]) { x }")).ParseProgram()
+	stmt, ok := program.Statements[0].(*ast.ForStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not *ast.ForStatement. got=%T", program.Statements[0])
+	}
+	if len(stmt.Variables) != 2 || stmt.Variables[0].Value != "i" || stmt.Variables[1].Value != "x" {
+		t.Errorf("wrong loop variables. got=%v", stmt.Variables)
+	}
+}
+func TestLoopStatementErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"break", "1:1: break outside loop"},
+		{"if (x) { continue }", "1:10: continue outside loop"},
+		{"while (x) { fn() { break } }", "1:20: break outside loop"},
+		{"for (x of arr) { x }", "1:8: expected next token to be IN,but got:IDENT instead"},
+		{"for (1 in arr) { x }", "1:6: expected next token to be IDENT,but got:INT instead"},
+	}
+
+	for _, tt := range tests {
+		parser := New(lexer.New(tt.input))
+		parser.ParseProgram()
+		errors := parser.Errors()
+		if len(errors) != 1 || errors[0] != tt.expected {
+			t.Errorf("input %q: expected=%q,got=%q", tt.input, tt.expected, errors)
+		}
+	}
+}
+func TestElseIfExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"if (a) { x } else if (b) { y } else { z }", "ifa xelse ifb yelse z"},
+		{"if (a) { x } else if (b) { y }", "ifa xelse ifb y"},
+		{"if (a) { x }\nelse if (b) { y }\nelse if (c) { z }", "ifa xelse ifb yelse ifc z"},
+	}
+
+	for _, tt := range tests {
+		parser := New(lexer.New(tt.input))
+		program := parser.ParseProgram()
+		chenckParserErrors(t, parser)
+		if program.String() != tt.expected {
+			t.Errorf("input %q: expected=%q,got=%q", tt.input, tt.expected, program.String())
+		}
+	}
+
+	program := New(lexer.New("if (a) { x } else if (b) { y } else { z }")).ParseProgram()
+	exp := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.IfExpression)
+	if len(exp.Alternative.Statements) != 1 {
+		t.Fatalf("alternative should contain 1 statement. got=%d", len(exp.Alternative.Statements))
+	}
+	nested, ok := exp.Alternative.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.IfExpression)
+	if !ok {
+		t.Fatalf("alternative is not *ast.IfExpression. got=%T", exp.Alternative.Statements[0])
+	}
+	if !testIdentifier(t, nested.Condition, "b") || nested.Alternative == nil {
+		t.Errorf("wrong nested if. got=%q", nested.String())
+	}
+	if exp.End().Column != 42 {
+		t.Errorf("wrong end position. got=%s", exp.End())
+	}
+
+	parser := New(lexer.New("if (a) { x } else if b { y }"))
+	parser.ParseProgram()
+	expected := "1:22: expected next token to be (,but got:IDENT instead"
+	if errors := parser.Errors(); len(errors) != 1 || errors[0] != expected {
+		t.Errorf("wrong errors. expected=%q,got=%q", expected, errors)
+	}
+}
+func TestPropertyExpression(t *testing.T) {
+	parser := New(lexer.New("user.name\n.upper()"))
+	program := parser.ParseProgram()
+	chenckParserErrors(t, parser)
+
+	call, ok := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.CallExpression)
+	if !ok {
+		t.Fatalf("expression is not *ast.CallExpression. got=%T", program.Statements[0])
+	}
+	method, ok := call.Function.(*ast.PropertyExpression)
+	if !ok || method.Property.Value != "upper" {
+		t.Fatalf("call.Function is not the upper property. got=%q", call.Function.String())
+	}
+	field, ok := method.Object.(*ast.PropertyExpression)
+	if !ok || !testIdentifier(t, field.Object, "user") || field.Property.Value != "name" {
+		t.Fatalf("wrong field access. got=%q", method.Object.String())
+	}
+	if call.Pos().Column != 1 || method.End().Line != 2 || method.End().Column != 7 {
+		t.Errorf("wrong positions. got=%s-%s", call.Pos(), method.End())
+	}
+
+	parser = New(lexer.New("a.1"))
+	parser.ParseProgram()
+	expected := "1:3: expected next token to be IDENT,but got:INT instead"
+	if errors := parser.Errors(); len(errors) != 1 || errors[0] != expected {
+		t.Errorf("wrong errors. expected=%q,got=%q", expected, errors)
+	}
+}
+func TestSliceExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a[1:3]", "(a[1:3])"},
+		{"a[:-1]", "(a[:(-1)])"},
+		{"a[1:]", "(a[1:])"},
+		{"a[:]", "(a[:])"},
+		{"a[::2]", "(a[::2])"},
+		{"a[::]", "(a[:])"},
+		{"a[i + 1:len(a) - 1:-1]", "(a[(i + 1):(len(a) - 1):(-1)])"},
+		{"a[1:][0]", "((a[1:])[0])"},
+	}
+
+	for _, tt := range tests {
+		parser := New(lexer.New(tt.input))
+		program := parser.ParseProgram()
+		chenckParserErrors(t, parser)
+		if program.String() != tt.expected {
+			t.Errorf("input %q: expected=%q,got=%q", tt.input, tt.expected, program.String())
+		}
+	}
+
+	program := New(lexer.New("arr[:2]")).ParseProgram()
+	exp, ok := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.SliceExpression)
+	if !ok {
+		t.Fatalf("expression is not *ast.SliceExpression. got=%T", program.Statements[0])
+	}
+	if exp.Start != nil || exp.Step != nil || !testIntergerLiteral(t, exp.Stop, 2) {
+		t.Errorf("wrong slice bounds. got=%q", exp.String())
+	}
+	if exp.End().Column != 8 {
+		t.Errorf("wrong end position. got=%s", exp.End())
+	}
+
+	parser := New(lexer.New("a[1:2:3:4]"))
+	parser.ParseProgram()
+	expected := "1:8: expected next token to be ],but got:: instead"
+	if errors := parser.Errors(); len(errors) != 1 || errors[0] != expected {
+		t.Errorf("wrong errors. expected=%q,got=%q", expected, errors)
+	}
+}
+func TestDestructuringLet(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let [a, b] = xs", "let [a, b] = xs;"},
+		{"let [a, ...rest] = xs", "let [a, ...rest] = xs;"},
+		{"let [...all] = xs", "let [...all] = xs;"},
+		{"let [] = xs", "let [] = xs;"},
+		{"let [a, [b, c]] = xs", "let [a, [b, c]] = xs;"},
+		{"let {name, age: years} = person", "let {name, age: years} = person;"},
+		{`let {"first-name": first, ...others} = person`, "let {first-name: first, ...others} = person;"},
+		{"let {pos: [x, y], tags: {main}} = item", "let {pos: [x, y], tags: {main}} = item;"},
+	}
+
+	for _, tt := range tests {
+		parser := New(lexer.New(tt.input))
+		program := parser.ParseProgram()
+		chenckParserErrors(t, parser)
+		if program.String() != tt.expected {
+			t.Errorf("input %q: expected=%q,got=%q", tt.input, tt.expected, program.String())
+		}
+	}
+
+	program := New(lexer.New("let [a, ...b] = c")).ParseProgram()
+	stmt := program.Statements[0].(*ast.LetStatement)
+	pattern, ok := stmt.Pattern.(*ast.ArrayPattern)
+	if !ok || stmt.Name != nil {
+		t.Fatalf("stmt.Pattern is not *ast.ArrayPattern. got=%T", stmt.Pattern)
+	}
+	if len(pattern.Elements) != 1 || !testIdentifier(t, pattern.Elements[0], "a") || pattern.Rest.Value != "b" {
+		t.Errorf("wrong pattern. got=%q", pattern.String())
+	}
+}
+func TestDestructuringLetErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let [a, ...b, c] = xs", "1:13: expected next token to be ],but got:, instead"},
+		{"let [1] = xs", "1:6: expected an identifier, [ or { in pattern,but got:INT instead"},
+		{"let {1: a} = xs", "1:6: expected a key in hash pattern,but got:INT instead"},
+		{`let {"a"} = xs`, "1:9: expected next token to be :,but got:} instead"},
+		{"let [a, b = xs", "1:11: expected next token to be ],but got:= instead"},
+	}
+
+	for _, tt := range tests {
+		parser := New(lexer.New(tt.input))
+		parser.ParseProgram()
+		errors := parser.Errors()
+		if len(errors) != 1 || errors[0] != tt.expected {
+			t.Errorf("input %q: expected=%q,got=%q", tt.input, tt.expected, errors)
+		}
+	}
+}
+func TestErrorRecoveryInsideBraces(t *testing.T) {
+	input := `let h = {
+	"a": oops oops
+}
+let {1: a} = xs
+let y = 2`
+	parser := New(lexer.New(input))
+	program := parser.ParseProgram()
+
+	expected := []string{
+		"2:12: expected next token to be },but got:IDENT instead",
+		"4:6: expected a key in hash pattern,but got:INT instead",
+	}
+	errors := parser.Errors()
+	if len(errors) != len(expected) {
+		t.Fatalf("wrong number of errors. expected=%q,got=%q", expected, errors)
+	}
+	for i, msg := range expected {
+		if errors[i] != msg {
+			t.Errorf("errors[%d] wrong. expected=%q,got=%q", i, msg, errors[i])
+		}
+	}
+	if len(program.Statements) != 1 {
+		t.Fatalf("len(program.Statements) != 1,got=%d", len(program.Statements))
+	}
+	testLetStatement(t, program.Statements[0], "y", "2")
+}
+func TestFunctionParameterDefaultsAndRest(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"fn(a, b = 10) { a }", "fn(a,b = 10)a"},
+		{"fn(a, b = a * 2, ...rest) { a }", "fn(a,b = (a * 2),...rest)a"},
+		{"fn(...args) { args }", "fn(...args)args"},
+		{"f(...args)", "f(...args)"},
+		{"f(1, ...xs, 2)", "f(1, ...xs, 2)"},
+		{"[...a, ...b[1:]]", "[...a, ...(b[1:])]"},
+		{`{...defaults, "a": 1, ...overrides}`, "{...defaults,a:1,...overrides}"},
+	}
+
+	for _, tt := range tests {
+		parser := New(lexer.New(tt.input))
+		program := parser.ParseProgram()
+		chenckParserErrors(t, parser)
+		if program.String() != tt.expected {
+			t.Errorf("input %q: expected=%q,got=%q", tt.input, tt.expected, program.String())
+		}
+	}
+
+	program := New(lexer.New("fn(x, y = 1, ...z) {}")).ParseProgram()
+	fn := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+	if len(fn.Parameters) != 2 || len(fn.Defaults) != 2 || fn.Defaults[0] != nil {
+		t.Fatalf("wrong parameters. got=%q", fn.String())
+	}
+	testIntergerLiteral(t, fn.Defaults[1], 1)
+	if fn.Rest == nil || fn.Rest.Value != "z" {
+		t.Errorf("wrong rest parameter. got=%v", fn.Rest)
+	}
+}
+func TestFunctionParameterErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"fn(a = 1, b) { a }", "1:11: parameter b without default value follows parameter with default value"},
+		{"fn(...a, b) { a }", "1:8: expected next token to be ),but got:, instead"},
+		{"fn(a,) { a }", "1:6: expected next token to be IDENT,but got:) instead"},
+		{"fn(...) { a }", "1:7: expected next token to be IDENT,but got:) instead"},
+	}
+
+	for _, tt := range tests {
+		parser := New(lexer.New(tt.input))
+		parser.ParseProgram()
+		errors := parser.Errors()
+		if len(errors) != 1 || errors[0] != tt.expected {
+			t.Errorf("input %q: expected=%q,got=%q", tt.input, tt.expected, errors)
+		}
+	}
+}
+func TestKeywordArguments(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`connect(host: "x", port: 8080)`, "connect(host: x, port: 8080)"},
+		{"f(1, ...xs, flag: a + b)", "f(1, ...xs, flag: (a + b))"},
+		{"f(a, b)", "f(a, b)"},
+	}
+
+	for _, tt := range tests {
+		parser := New(lexer.New(tt.input))
+		program := parser.ParseProgram()
+		chenckParserErrors(t, parser)
+		if program.String() != tt.expected {
+			t.Errorf("input %q: expected=%q,got=%q", tt.input, tt.expected, program.String())
+		}
+	}
+
+	program := New(lexer.New("f(1, name: 2)")).ParseProgram()
+	call := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.CallExpression)
+	if len(call.Arguments) != 1 || len(call.Keywords) != 1 || call.Keywords[0].Name.Value != "name" {
+		t.Fatalf("wrong arguments. got=%q", call.String())
+	}
+	testIntergerLiteral(t, call.Keywords[0].Value, 2)
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{"f(a: 1, 2)", "1:9: positional argument follows keyword argument"},
+		{"f(a: 1, a: 2)", "1:9: duplicate keyword argument: a"},
+		{"f(a: 1 b: 2)", "1:8: expected next token to be ),but got:IDENT instead"},
+	}
+	for _, tt := range errorTests {
+		parser := New(lexer.New(tt.input))
+		parser.ParseProgram()
+		errors := parser.Errors()
+		if len(errors) != 1 || errors[0] != tt.expected {
+			t.Errorf("input %q: expected=%q,got=%q", tt.input, tt.expected, errors)
+		}
+	}
+}
+func TestArrowFunctions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"x => x * 2", "fn(x)(x * 2)"},
+		{"(x) => x * 2", "fn(x)(x * 2)"},
+		{"() => 1", "fn()1"},
+		{"(a, b = 10, ...rest) => a + b", "fn(a,b = 10,...rest)(a + b)"},
+		{"x => { let y = x; y }", "fn(x)let y = x;y"},
+		{"map(xs, x => x + 1)", "map(xs, fn(x)(x + 1))"},
+		{"x => y => x + y", "fn(x)fn(y)(x + y)"},
+		{"(x => x)(1)", "fn(x)x(1)"},
+		{"(1 + 2) * 3", "((1 + 2) * 3)"},
+		{"(a)", "a"},
+	}
+
+	for _, tt := range tests {
+		parser := New(lexer.New(tt.input))
+		program := parser.ParseProgram()
+		chenckParserErrors(t, parser)
+		if program.String() != tt.expected {
+			t.Errorf("input %q: expected=%q,got=%q", tt.input, tt.expected, program.String())
+		}
+	}
+
+	program := New(lexer.New("(x, y = 1) => x")).ParseProgram()
+	fn, ok := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+	if !ok {
+		t.Fatalf("expression is not *ast.FunctionLiteral. got=%T", program.Statements[0].(*ast.ExpressionStatement).Expression)
+	}
+	if len(fn.Parameters) != 2 || len(fn.Defaults) != 2 || fn.Defaults[0] != nil {
+		t.Fatalf("wrong parameters. got=%q", fn.String())
+	}
+	testIntergerLiteral(t, fn.Defaults[1], 1)
+	if len(fn.Body.Statements) != 1 {
+		t.Fatalf("body should have 1 statement. got=%d", len(fn.Body.Statements))
+	}
+}
+func TestArrowFunctionErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"(a = 1, b) => a", "1:9: parameter b without default value follows parameter with default value"},
+		{"(1) => 1", "1:2: invalid arrow function parameter: 1"},
+		{"(...a, b) => a", "1:2: invalid arrow function parameter: ...a"},
+		{"(a, b)", "1:7: expected => after parameter list,but got:EOF instead"},
+		{"()", "1:3: expected next token to be =>,but got:EOF instead"},
+		{"let f = (a, =) => a", "1:13: expected an expression,but got:= instead"},
+		{"(]) => 1", "1:2: expected an expression,but got:] instead"},
+		{"( if ) => 1", "1:6: expected next token to be (,but got:) instead"},
+		{"f((if) => 1)", "1:6: expected next token to be (,but got:) instead"},
+		{"(a = ) => a", "1:6: expected an expression,but got:) instead"},
+	}
+
+	for _, tt := range tests {
+		parser := New(lexer.New(tt.input))
+		parser.ParseProgram()
+		errors := parser.Errors()
+		if len(errors) != 1 || errors[0] != tt.expected {
+			t.Errorf("input %q: expected=%q,got=%q", tt.input, tt.expected, errors)
+		}
+	}
+}
+func TestMatchExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`match (x) { 1 => "one", _ => 0 }`, "matchx {1 => one, _ => 0}"},
+		{"match (x) { -1 => a, 1.5 => b, true => c, }", "matchx {(-1) => a, 1.5 => b, true => c}"},
+		{"match (xs) { [a, b] => a + b, [a, ...] => a, [a, ...rest] => rest }", "matchxs {[a, b] => (a + b), [a, ...] => a, [a, ...rest] => rest}"},
+		{`match (h) { {"type": "point", x, y: [1, z]} => x, {kind, ...} => kind }`, "matchh {{type: point, x, y: [1, z]} => x, {kind, ...} => kind}"},
+		{"match (n) { x if x > 0 => { let y = x; y } _ => 0 }", "matchn {x if (x > 0) => let y = x;y, _ => 0}"},
+		{"match (n) {\n 0 => a\n _ => b\n}", "matchn {0 => a, _ => b}"},
+		{"let r = match (n) { _ => n * 2 }", "let r = matchn {_ => (n * 2)};"},
+		{"match (n) {}", "matchn {}"},
+		{"match (n) { x if x > y => x }", "matchn {x if (x > y) => x}"},
+		{"match (n) { x if all(xs, y => y > x) => x => x }", "matchn {x if all(xs, fn(y)(y > x)) => fn(x)x}"},
+		{"match (n) { x if (y => y)(x) => 1 }", "matchn {x if fn(y)y(x) => 1}"},
+	}
+
+	for _, tt := range tests {
+		parser := New(lexer.New(tt.input))
+		program := parser.ParseProgram()
+		chenckParserErrors(t, parser)
+		if program.String() != tt.expected {
+			t.Errorf("input %q: expected=%q,got=%q", tt.input, tt.expected, program.String())
+		}
+	}
+
+	program := New(lexer.New("match (n) { [a] if a => 1 }")).ParseProgram()
+	match, ok := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.MatchExpression)
+	if !ok {
+		t.Fatalf("expression is not *ast.MatchExpression. got=%T", program.Statements[0].(*ast.ExpressionStatement).Expression)
+	}
+	if len(match.Arms) != 1 {
+		t.Fatalf("match should have 1 arm. got=%d", len(match.Arms))
+	}
+	if _, ok := match.Arms[0].Pattern.(*ast.ArrayPattern); !ok {
+		t.Errorf("pattern is not *ast.ArrayPattern. got=%T", match.Arms[0].Pattern)
+	}
+	testIdentifier(t, match.Arms[0].Guard, "a")
+}
+func TestMatchExpressionErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"match (x) { a + 1 => 1 }", "1:15: expected next token to be =>,but got:+ instead"},
+		{"match (x) { (a) => 1 }", "1:13: expected a literal, identifier, [ or { in pattern,but got:( instead"},
+		{"match (x) { -a => 1 }", "1:13: expected a literal, identifier, [ or { in pattern,but got:- instead"},
+		{"match (x) { 1 => a 2 => b }", "1:20: expected , or } after match arm,but got:INT instead"},
+		{"match x { _ => 1 }", "1:7: expected next token to be (,but got:IDENT instead"},
+		{"let [a, ...] = xs", "1:12: expected next token to be IDENT,but got:] instead"},
+	}
+
+	for _, tt := range tests {
+		parser := New(lexer.New(tt.input))
+		parser.ParseProgram()
+		errors := parser.Errors()
+		if len(errors) != 1 || errors[0] != tt.expected {
+			t.Errorf("input %q: expected=%q,got=%q", tt.input, tt.expected, errors)
+		}
+	}
+}
+func TestNullAndOptionalChaining(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"null", "null"},
+		{"a ?? b", "(a ?? b)"},
+		{"a ?? b ?? c", "((a ?? b) ?? c)"},
+		{"a || b ?? c", "((a || b) ?? c)"},
+		{"x = a ?? 1", "(x = (a ?? 1))"},
+		{"a?.b", "(a?.b)"},
+		{"a?.b.c", "((a?.b).c)"},
+		{"a?[0]", "(a?[0])"},
+		{"a?[1:2]", "(a?[1:2])"},
+		{"f?.(1, x: 2)", "f?.(1, x: 2)"},
+		{"a?.b?.(1)", "(a?.b)?.(1)"},
+		{"a?.b ?? -1", "((a?.b) ?? (-1))"},
+		{"match (x) { null => 0, _ => 1 }", "matchx {null => 0, _ => 1}"},
+	}
+
+	for _, tt := range tests {
+		parser := New(lexer.New(tt.input))
+		program := parser.ParseProgram()
+		chenckParserErrors(t, parser)
+		if program.String() != tt.expected {
+			t.Errorf("input %q: expected=%q,got=%q", tt.input, tt.expected, program.String())
+		}
+	}
+
+	program := New(lexer.New("a?.b")).ParseProgram()
+	exp, ok := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.PropertyExpression)
+	if !ok || !exp.Optional {
+		t.Errorf("expected optional *ast.PropertyExpression. got=%#v", program.Statements[0].(*ast.ExpressionStatement).Expression)
+	}
+}
+func TestOptionalChainingErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a?.true", "1:4: expected next token to be IDENT,but got:true instead"},
+		{"a?.1", "1:5: expected next token to be :,but got:EOF instead"},
+		{"a?[1", "1:5: expected next token to be ],but got:EOF instead"},
+	}
+
+	for _, tt := range tests {
+		parser := New(lexer.New(tt.input))
+		parser.ParseProgram()
+		errors := parser.Errors()
+		if len(errors) != 1 || errors[0] != tt.expected {
+			t.Errorf("input %q: expected=%q,got=%q", tt.input, tt.expected, errors)
+		}
+	}
+}
+func TestConditionalAndComparisonChain(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a ? b : c", "(a ? b : c)"},
+		{"a ? b : c ? d : e", "(a ? b : (c ? d : e))"},
+		{"a ? b ? c : d : e", "(a ? (b ? c : d) : e)"},
+		{"x = a ? 1 : 2", "(x = (a ? 1 : 2))"},
+		{"a ?? b ? c : d", "((a ?? b) ? c : d)"},
+		{"a || b ? c + 1 : d * 2", "((a || b) ? (c + 1) : (d * 2))"},
+		{"a > 0 ? [1] : [2]", "((a > 0) ? [1] : [2])"},
+		{"f(a ? b : c, k: x ? 1 : 2)", "f((a ? b : c), k: (x ? 1 : 2))"},
+		{`{"k": a ? 1 : 2}`, "{k:(a ? 1 : 2)}"},
+		{"xs[a ? 0 : 1]", "(xs[(a ? 0 : 1)])"},
+		{"flag ?.5 : 1", "(flag ? .5 : 1)"},
+		{"flag ? .5 : 1", "(flag ? .5 : 1)"},
+		{"0 <= x < 10", "(0 <= x < 10)"},
+		{"a < b > c <= d >= e", "(a < b > c <= d >= e)"},
+		{"a + 1 < b * 2 < c", "((a + 1) < (b * 2) < c)"},
+		{"a < b == c < d", "((a < b) == (c < d))"},
+		{"(a < b) < c", "((a < b) < c)"},
+		{"a < b && b < c", "((a < b) && (b < c))"},
+		{"0 <= x < 10 ? x : 0", "((0 <= x < 10) ? x : 0)"},
+	}
+
+	for _, tt := range tests {
+		parser := New(lexer.New(tt.input))
+		program := parser.ParseProgram()
+		chenckParserErrors(t, parser)
+		if program.String() != tt.expected {
+			t.Errorf("input %q: expected=%q,got=%q", tt.input, tt.expected, program.String())
+		}
+	}
+
+	program := New(lexer.New("a < b <= c")).ParseProgram()
+	chain, ok := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.ComparisonChain)
+	if !ok {
+		t.Fatalf("expression is not *ast.ComparisonChain. got=%T", program.Statements[0].(*ast.ExpressionStatement).Expression)
+	}
+	if len(chain.Operands) != 3 || len(chain.Operators) != 2 || chain.Operators[0] != "<" || chain.Operators[1] != "<=" {
+		t.Errorf("wrong comparison chain. got=%q", chain.String())
+	}
+	program = New(lexer.New("a < b")).ParseProgram()
+	if _, ok := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.InfixExpression); !ok {
+		t.Errorf("single comparison should be *ast.InfixExpression. got=%T", program.Statements[0].(*ast.ExpressionStatement).Expression)
+	}
+}
+func TestConditionalErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a ? b", "1:6: expected next token to be :,but got:EOF instead"},
+		{"a ? b c", "1:7: expected next token to be :,but got:IDENT instead"},
+		{"a ? : c", "1:5: expected an expression,but got:: instead"},
+	}
+
+	for _, tt := range tests {
+		parser := New(lexer.New(tt.input))
+		parser.ParseProgram()
+		errors := parser.Errors()
+		if len(errors) != 1 || errors[0] != tt.expected {
+			t.Errorf("input %q: expected=%q,got=%q", tt.input, tt.expected, errors)
+		}
+	}
+}
+func TestConstStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"const x = 1", "const x = 1;"},
+		{"const [a, ...rest] = xs", "const [a, ...rest] = xs;"},
+		{"const {name} = h\nname", "const {name} = h;name"},
+		{"let x = 1; const x = 2", "let x = 1;const x = 2;"},
+		{"const x = 1; let f = fn(x) { x = 2 }", "const x = 1;let f = fn(x)(x = 2);"},
+		{"const x = 1; let f = x => { x = 2 }", "const x = 1;let f = fn(x)(x = 2);"},
+		{"const x = 1; let f = fn(...x) { x = 2 }", "const x = 1;let f = fn(...x)(x = 2);"},
+		{"const x = 1; let f = fn() { let x = 2; x = 3 }", "const x = 1;let f = fn()let x = 2;(x = 3);"},
+		{"const x = 1; for (x in xs) { x = 2 }", "const x = 1;for(x in xs) (x = 2)"},
+		{"const x = 1; match (1) { x => { x = 2 } }", "const x = 1;match1 {x => (x = 2)}"},
+		{"const x = 1; match (1) { [x] => { x = 2 } }", "const x = 1;match1 {[x] => (x = 2)}"},
+		{"const h = {}; h.a = 1; h[0] = 2", "const h = {};((h.a) = 1)((h[0]) = 2)"},
+		{"const a = 5; let f = (a = 1) => a", "const a = 5;let f = fn(a = 1)a;"},
+		{"const a = 5; let f = (b, a = 1) => a", "const a = 5;let f = fn(b,a = 1)a;"},
+	}
+
+	for _, tt := range tests {
+		parser := New(lexer.New(tt.input))
+		program := parser.ParseProgram()
+		chenckParserErrors(t, parser)
+		if program.String() != tt.expected {
+			t.Errorf("input %q: expected=%q,got=%q", tt.input, tt.expected, program.String())
+		}
+	}
+
+	program := New(lexer.New("const x = 1")).ParseProgram()
+	stmt, ok := program.Statements[0].(*ast.LetStatement)
+	if !ok || !stmt.IsConst() {
+		t.Errorf("expected const *ast.LetStatement. got=%#v", program.Statements[0])
+	}
+}
+func TestConstErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"const x = 1; x = 2", "1:14: cannot assign to constant x"},
+		{"const x = 1; x += 1", "1:14: cannot assign to constant x"},
+		{"const x = 1; let x = 2", "1:18: cannot redeclare constant x"},
+		{"const x = 1; const x = 2", "1:20: cannot redeclare constant x"},
+		{"const [a, b] = xs; b = 1", "1:20: cannot assign to constant b"},
+		{"const {a, ...r} = h; r = 1", "1:22: cannot assign to constant r"},
+		{"const x = 1; let f = fn() { x = 2 }", "1:29: cannot assign to constant x"},
+		{"const x = 1; if (true) { let x = 2 }", "1:30: cannot redeclare constant x"},
+		{"const x = 1; while (true) { x = 2 }", "1:29: cannot assign to constant x"},
+		{"const a = 5; (a = 1)", "1:15: cannot assign to constant a"},
+		{"const a = 5; ((a) = 1)", "1:16: cannot assign to constant a"},
+		{"const a = 5; (b = a = 1)", "1:19: cannot assign to constant a"},
+		{"const a = 5; (a += 1)", "1:15: cannot assign to constant a"},
+		{"const a = 5; let f = (b = (a = 1)) => b", "1:28: cannot assign to constant a"},
+		{"const x", "1:8: expected next token to be =,but got:EOF instead"},
+	}
+
+	for _, tt := range tests {
+		parser := New(lexer.New(tt.input))
+		parser.ParseProgram()
+		errors := parser.Errors()
+		if len(errors) != 1 || errors[0] != tt.expected {
+			t.Errorf("input %q: expected=%q,got=%q", tt.input, tt.expected, errors)
+		}
+	}
+}
+func TestIncrementExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"x++", "(x++)"},
+		{"--x", "(--x)"},
+		{"a[i]++", "((a[i])++)"},
+		{"++h.n", "(++(h.n))"},
+		{"-x++", "(-(x++))"},
+		{"++a[0] * 2", "((++(a[0])) * 2)"},
+		{"y = x--", "(y = (x--))"},
+		{"x\n++y", "x(++y)"},
+		{"let i = 0; i++", "let i = 0;(i++)"},
+		{"--5", "(-(-5))"},
+		{"---5", "(-(-(-5)))"},
+		{"--(a + b)", "(-(-(a + b)))"},
+		{"x--y", "(x - (-y))"},
+		{"x--1", "(x - (-1))"},
+		{"x--y * 2", "(x - ((-y) * 2))"},
+		{"x-- - y", "((x--) - y)"},
+		{"a[0]--", "((a[0])--)"},
+	}
+
+	for _, tt := range tests {
+		parser := New(lexer.New(tt.input))
+		program := parser.ParseProgram()
+		chenckParserErrors(t, parser)
+		if program.String() != tt.expected {
+			t.Errorf("input %q: expected=%q,got=%q", tt.input, tt.expected, program.String())
+		}
+	}
+}
+func TestIncrementErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"const x = 1; x++", "1:14: cannot assign to constant x"},
+		{"const x = 1; --x", "1:16: cannot assign to constant x"},
+		{"x++ y", "1:5: expected ; or newline after statement,but got:IDENT instead"},
+		{"5++", "1:1: invalid ++ operand: 5"},
+		{"(a + b)++", "1:2: invalid ++ operand: (a + b)"},
+		{"f()--", "1:1: invalid -- operand: f()"},
+		{"++5", "1:3: invalid ++ operand: 5"},
+		{"x = 1 + 2--", "1:9: invalid -- operand: 2"},
+	}
+
+	for _, tt := range tests {
+		parser := New(lexer.New(tt.input))
+		parser.ParseProgram()
+		errors := parser.Errors()
+		if len(errors) != 1 || errors[0] != tt.expected {
+			t.Errorf("input %q: expected=%q,got=%q", tt.input, tt.expected, errors)
+		}
+	}
+}
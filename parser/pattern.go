@@ -0,0 +1,127 @@
+package parser
+
+import (
+	"fmt"
+	"interpreter/ast"
+	"interpreter/token"
+)
+
+// 解析当前token开始的解构模式：标识符、[...] 或 {...}
+func (p *Parser) parsePattern() ast.Expression {
+	switch p._curToken.Type {
+	case token.IDENT:
+		return &ast.Identifier{Token: p._curToken, Value: p._curToken.Literal}
+	case token.LBRACKET:
+		return p.parseArrayPattern(false)
+	case token.LBRACE:
+		return p.parseHashPattern(false)
+	}
+	p.reportError(&ParseError{
+		Pos:      p._curToken.Pos,
+		Expected: "pattern",
+		Actual:   p._curToken,
+		Message:  fmt.Sprintf("expected an identifier, [ or { in pattern,but got:%s instead", p._curToken.Type),
+	})
+	return nil
+}
+
+/*
+[a, [b, c], ...rest] ，...rest只能是最后一项
+match为true时解析match分支中的模式，其中的元素可以是字面量，结尾可以是不带名字的 ...
+*/
+func (p *Parser) parseArrayPattern(match bool) ast.Expression {
+	pattern := &ast.ArrayPattern{Token: p._curToken}
+
+	for !p.peekTokenIs(token.RBRACKET) {
+		p.nextToken()
+		if p.curTokenIs(token.ELLIPSIS) {
+			if match && p.peekTokenIs(token.RBRACKET) {
+				pattern.Open = true
+				break
+			}
+			if !p.expectedPeek(token.IDENT) {
+				return nil
+			}
+			pattern.Rest = &ast.Identifier{Token: p._curToken, Value: p._curToken.Literal}
+			break
+		}
+		element := p.parseSubPattern(match)
+		if element == nil {
+			return nil
+		}
+		pattern.Elements = append(pattern.Elements, element)
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+	if !p.expectedPeek(token.RBRACKET) {
+		return nil
+	}
+	pattern.RBracket = p._curToken
+
+	return pattern
+}
+
+// {name, age: years, "first-name": first, ...rest}
+func (p *Parser) parseHashPattern(match bool) ast.Expression {
+	pattern := &ast.HashPattern{Token: p._curToken}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+		if p.curTokenIs(token.ELLIPSIS) {
+			if match && p.peekTokenIs(token.RBRACE) {
+				pattern.Open = true
+				break
+			}
+			if !p.expectedPeek(token.IDENT) {
+				return nil
+			}
+			pattern.Rest = &ast.Identifier{Token: p._curToken, Value: p._curToken.Literal}
+			break
+		}
+		if !p.curTokenIs(token.IDENT) && !p.curTokenIs(token.STRING) {
+			p.reportError(&ParseError{
+				Pos:      p._curToken.Pos,
+				Expected: token.IDENT,
+				Actual:   p._curToken,
+				Message:  fmt.Sprintf("expected a key in hash pattern,but got:%s instead", p._curToken.Type),
+			})
+			return nil
+		}
+		field := &ast.HashPatternField{Key: p._curToken.Literal}
+		if p.peekTokenIs(token.COLON) {
+			p.nextToken()
+			p.nextToken()
+			field.Value = p.parseSubPattern(match)
+			if field.Value == nil {
+				return nil
+			}
+		} else if p.curTokenIs(token.IDENT) {
+			field.Value = &ast.Identifier{Token: p._curToken, Value: p._curToken.Literal}
+		} else {
+			//字符串key必须指定变量名
+			if !p.expectedPeek(token.COLON) {
+				return nil
+			}
+		}
+		pattern.Fields = append(pattern.Fields, field)
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+	if !p.expectedPeek(token.RBRACE) {
+		return nil
+	}
+	pattern.RBrace = p._curToken
+
+	return pattern
+}
+
+func (p *Parser) parseSubPattern(match bool) ast.Expression {
+	if match {
+		return p.parseMatchPattern()
+	}
+	return p.parsePattern()
+}
@@ -0,0 +1,80 @@
+package parser
+
+import (
+	"fmt"
+	"interpreter/ast"
+)
+
+/*
+在解析时检查常量：记录每层作用域中声明的名字及其是否为常量，
+对常量赋值或在同一作用域中重新声明常量时报错
+作用域的划分与求值时一致：程序、函数体、每次循环迭代、match分支各自是一个作用域，if的语句块不是
+这里只能发现源码中可见的错误，例如在REPL中跨行的赋值，由求值时的检查负责
+*/
+func (p *Parser) pushScope() {
+	p._scopes = append(p._scopes, map[string]bool{})
+}
+func (p *Parser) popScope() {
+	p._scopes = p._scopes[:len(p._scopes)-1]
+}
+
+// 在当前作用域中声明名字，常量不能在同一作用域中重新声明
+func (p *Parser) declare(ident *ast.Identifier, constant bool) {
+	scope := p._scopes[len(p._scopes)-1]
+	if scope[ident.Value] {
+		p.addError(ident.Token, fmt.Sprintf("cannot redeclare constant %s", ident.Value))
+		return
+	}
+	scope[ident.Value] = constant
+}
+
+// 声明解构模式或match模式中绑定的所有名字
+func (p *Parser) declarePattern(pattern ast.Expression, constant bool) {
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		p.declare(pattern, constant)
+	case *ast.ArrayPattern:
+		for _, element := range pattern.Elements {
+			p.declarePattern(element, constant)
+		}
+		if pattern.Rest != nil {
+			p.declare(pattern.Rest, constant)
+		}
+	case *ast.HashPattern:
+		for _, field := range pattern.Fields {
+			p.declarePattern(field.Value, constant)
+		}
+		if pattern.Rest != nil {
+			p.declare(pattern.Rest, constant)
+		}
+	}
+}
+
+func (p *Parser) declareParameters(lit *ast.FunctionLiteral) {
+	for _, param := range lit.Parameters {
+		p.declare(param, false)
+	}
+	if lit.Rest != nil {
+		p.declare(lit.Rest, false)
+	}
+}
+
+// 赋值目标为标识符时，找到声明它的最内层作用域，其中是常量则报错
+func (p *Parser) checkAssign(ident *ast.Identifier) {
+	for i := len(p._scopes) - 1; i >= 0; i-- {
+		if constant, ok := p._scopes[i][ident.Value]; ok {
+			if constant {
+				p.addError(ident.Token, fmt.Sprintf("cannot assign to constant %s", ident.Value))
+			}
+			return
+		}
+	}
+}
+
+/*
+(a = 1) 在看到后面的 => 之前无法确定是赋值还是参数a的默认值，因此括号中直接以 a = 开头的元素推迟检查
+元素中更深处的赋值，例如 (b = a = 1) 中的 a = 1，不可能是参数，照常检查
+*/
+func (p *Parser) isGroupDefault(ident *ast.Identifier) bool {
+	return ident.Token.Pos == p._groupElement
+}
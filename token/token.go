@@ -1,19 +1,56 @@
 package token
 
+import "fmt"
+
 // 定义了表达式中，变量，关键词，操作符
 type TokenType string
 
 type Token struct {
 	Type    TokenType
 	Literal string
+	Pos     Position //token第一个字符的位置
+	End     Position //token最后一个字符之后的位置
+
+	Comments      []string //紧挨在token之前的注释，包含注释符号，供格式化等工具使用
+	NewlineBefore bool     //token与上一个token之间有换行，换行可以结束一条语句
+}
+
+// Position 记录源码中的位置，Line与Column从1开始计数，Offset为字节偏移
+type Position struct {
+	Filename string
+	Offset   int
+	Line     int
+	Column   int
+}
+
+// IsValid 判断位置是否有效，零值Position表示未知位置
+func (p Position) IsValid() bool { return p.Line > 0 }
+
+// String 返回 file:line:column 形式的位置，没有文件名时返回 line:column
+func (p Position) String() string {
+	if !p.IsValid() {
+		if p.Filename != "" {
+			return p.Filename
+		}
+		return "-"
+	}
+	if p.Filename != "" {
+		return fmt.Sprintf("%s:%d:%d", p.Filename, p.Line, p.Column)
+	}
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
 }
 
 const (
 	ILLEGAL = "ILLEGAL"
 	EOF     = "EOF" //END OF FILE
 	STRING  = "STRING"
-	IDENT   = "IDENT" //i j foo
-	INT     = "INT"   //1 2 3 123
+	//带插值的字符串 "a${x}b${y}c" 分为 TEMPLATE_HEAD(a) TEMPLATE_MIDDLE(b) TEMPLATE_TAIL(c) 三种片段
+	TEMPLATE_HEAD   = "TEMPLATE_HEAD"
+	TEMPLATE_MIDDLE = "TEMPLATE_MIDDLE"
+	TEMPLATE_TAIL   = "TEMPLATE_TAIL"
+	IDENT           = "IDENT" //i j foo
+	INT             = "INT"   //1 2 3 123
+	FLOAT           = "FLOAT" //1.5 0.25 1e-9
 
 	BANG      = "!"
 	ASSIGN    = "="
@@ -21,7 +58,13 @@ const (
 	MINUS     = "-"
 	SLASH     = "/"
 	ASTERISK  = "*"
+	PERCENT   = "%"
+	POWER     = "**"
+	TILDE     = "~"
 	COMMA     = ","
+	DOT       = "."
+	ELLIPSIS  = "..."
+	ARROW     = "=>"
 	SEMICOLON = ";"
 	COLON     = ":"
 	LPAREN    = "("
@@ -38,6 +81,37 @@ const (
 	EQ       = "=="
 	NOT_EQ   = "!="
 
+	AND         = "&&"
+	OR          = "||"
+	BIT_AND     = "&"
+	BIT_OR      = "|"
+	BIT_XOR     = "^"
+	SHIFT_LEFT  = "<<"
+	SHIFT_RIGHT = ">>"
+
+	//复合赋值运算符
+	PLUS_ASSIGN        = "+="
+	MINUS_ASSIGN       = "-="
+	ASTERISK_ASSIGN    = "*="
+	SLASH_ASSIGN       = "/="
+	PERCENT_ASSIGN     = "%="
+	POWER_ASSIGN       = "**="
+	BIT_AND_ASSIGN     = "&="
+	BIT_OR_ASSIGN      = "|="
+	BIT_XOR_ASSIGN     = "^="
+	SHIFT_LEFT_ASSIGN  = "<<="
+	SHIFT_RIGHT_ASSIGN = ">>="
+
+	//自增与自减，可以写在变量、下标或字段之前或之后
+	INCREMENT = "++"
+	DECREMENT = "--"
+
+	//null合并、可选链与条件表达式
+	QUESTION          = "?"
+	NULLISH           = "??"
+	OPTIONAL_DOT      = "?." //obj?.name 与 f?.()
+	OPTIONAL_LBRACKET = "?["
+
 	IF       = "if"
 	ELSE     = "else"
 	RETURN   = "RETURN"
@@ -45,4 +119,12 @@ const (
 	FALSE    = "false "
 	FUNCTION = "FUNCTION"
 	LET      = "LET"
+	WHILE    = "WHILE"
+	FOR      = "FOR"
+	IN       = "IN"
+	BREAK    = "BREAK"
+	CONTINUE = "CONTINUE"
+	MATCH    = "MATCH"
+	NULL     = "NULL"
+	CONST    = "CONST"
 )